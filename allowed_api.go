@@ -0,0 +1,156 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin/v3/constant"
+)
+
+// GetAllowedActions returns the actions that subject can perform on object, in the order they appear in the policy.
+// domain is required when the request definition has a "dom" token.
+// The candidate actions are the distinct values of the "act" field of the policy rules, each of them is decided
+// by enforcing the request with it as the action, so deny rules, priorities and action patterns are honored.
+// A value of the "act" field is a candidate whatever its syntax, so a pattern, e.g. the keyMatch action "*",
+// is returned when the matcher allows it as the action of the request.
+// All the candidate actions are returned when the enforcer is disabled.
+//
+// For example, with the policy:
+// p, alice, data1, read
+// p, alice, data1, write
+// p, bob, data2, read
+//
+// GetAllowedActions("alice", "data1") will return ["read", "write"].
+func (e *Enforcer) GetAllowedActions(subject string, object string, domain ...string) ([]string, error) {
+	return e.GetNamedAllowedActions(NewEnforceContext(""), subject, object, domain...)
+}
+
+// GetNamedAllowedActions is GetAllowedActions using the request, policy, effect and matcher definitions of enforceContext.
+func (e *Enforcer) GetNamedAllowedActions(enforceContext EnforceContext, subject string, object string, domain ...string) ([]string, error) {
+	assertion, ok := e.model["p"][enforceContext.PType]
+	if !ok {
+		return nil, fmt.Errorf("missing policy definition %s", enforceContext.PType)
+	}
+	index, err := e.model.GetFieldIndex(enforceContext.PType, constant.ActionIndex)
+	if err != nil {
+		return nil, err
+	}
+	rvals, err := e.allowedRequest(enforceContext, subject, object, "", domain)
+	if err != nil {
+		return nil, err
+	}
+	actionIndex := -1
+	for i, token := range e.model["r"][enforceContext.RType].Tokens {
+		if token == enforceContext.RType+"_"+constant.ActionIndex {
+			actionIndex = i
+		}
+	}
+	if actionIndex == -1 {
+		return nil, fmt.Errorf("missing %s token in request definition %s", constant.ActionIndex, enforceContext.RType)
+	}
+
+	var candidates []string
+	seen := make(map[string]struct{})
+	for _, rule := range assertion.Policy {
+		if index >= len(rule) {
+			continue
+		}
+		if _, ok := seen[rule[index]]; ok {
+			continue
+		}
+		seen[rule[index]] = struct{}{}
+		candidates = append(candidates, rule[index])
+	}
+
+	actions := []string{}
+	if !e.enabled {
+		return append(actions, candidates...), nil
+	}
+	batch := newBatchEnforceState()
+	for _, action := range candidates {
+		rvals[actionIndex] = action
+		ok, err := e.enforce(context.Background(), "", nil, append([]interface{}{enforceContext, batch}, rvals...)...)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// FilterAllowed returns the objects on which subject can perform action, in the order of objects.
+// domain is required when the request definition has a "dom" token.
+// The matcher is compiled once and, unless disabled by EnableGFunctionCache, the g() results are shared by all objects.
+//
+// For example, with the policy:
+// p, alice, /data/*, read
+// p, alice, /private/*, write
+//
+// FilterAllowed("alice", "read", []string{"/data/1", "/private/1"}) will return ["/data/1"].
+func (e *Enforcer) FilterAllowed(subject string, action string, objects []string, domain ...string) ([]string, error) {
+	return e.FilterNamedAllowed(NewEnforceContext(""), subject, action, objects, domain...)
+}
+
+// FilterNamedAllowed is FilterAllowed using the request, policy, effect and matcher definitions of enforceContext.
+func (e *Enforcer) FilterNamedAllowed(enforceContext EnforceContext, subject string, action string, objects []string, domain ...string) ([]string, error) {
+	batch := newBatchEnforceState()
+	allowed := []string{}
+	for _, object := range objects {
+		rvals, err := e.allowedRequest(enforceContext, subject, object, action, domain)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := e.enforce(context.Background(), "", nil, append([]interface{}{enforceContext, batch}, rvals...)...)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, object)
+		}
+	}
+	return allowed, nil
+}
+
+// allowedRequest returns the request values built from the request definition of enforceContext.
+func (e *Enforcer) allowedRequest(enforceContext EnforceContext, subject, object, action string, domain []string) ([]interface{}, error) {
+	assertion, ok := e.model["r"][enforceContext.RType]
+	if !ok {
+		return nil, fmt.Errorf("missing request definition %s", enforceContext.RType)
+	}
+	rvals := make([]interface{}, 0, len(assertion.Tokens))
+	for _, token := range assertion.Tokens {
+		switch strings.TrimPrefix(token, enforceContext.RType+"_") {
+		case constant.SubjectIndex:
+			rvals = append(rvals, subject)
+		case constant.ObjectIndex:
+			rvals = append(rvals, object)
+		case constant.ActionIndex:
+			rvals = append(rvals, action)
+		case constant.DomainIndex:
+			if len(domain) == 0 {
+				return nil, fmt.Errorf("a domain is required by the request definition")
+			}
+			rvals = append(rvals, domain[0])
+		default:
+			return nil, fmt.Errorf("unsupported request token: %s", token)
+		}
+	}
+	return rvals, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/util"
+)
+
+func testGetAllowedActions(t *testing.T, e *Enforcer, sub, obj string, dom []string, res []string) {
+	t.Helper()
+	myRes, err := e.GetAllowedActions(sub, obj, dom...)
+	if err != nil {
+		t.Errorf("GetAllowedActions Error: %s", err)
+	} else if !util.ArrayEquals(res, myRes) {
+		t.Errorf("%s, %s: %v, supposed to be %v", sub, obj, myRes, res)
+	}
+}
+
+func TestGetAllowedActions(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	_, _ = e.AddPolicy("alice", "data1", "write")
+
+	testGetAllowedActions(t, e, "alice", "data1", nil, []string{"read", "write"})
+	testGetAllowedActions(t, e, "alice", "data2", nil, []string{"read", "write"})
+	testGetAllowedActions(t, e, "bob", "data1", nil, []string{})
+	testGetAllowedActions(t, e, "bob", "data2", nil, []string{"write"})
+
+	e, _ = NewEnforcer("examples/rbac_with_deny_model.conf", "examples/rbac_with_deny_policy.csv")
+	testGetAllowedActions(t, e, "alice", "data2", nil, []string{"read"})
+
+	// The action "(GET)|(POST)" is a candidate as well, it is allowed by regexMatch(r.act, p.act) with the rule of GET.
+	e, _ = NewEnforcer("examples/keymatch_model.conf", "examples/keymatch_policy.csv")
+	testGetAllowedActions(t, e, "alice", "/alice_data/resource1", nil, []string{"GET", "POST", "(GET)|(POST)"})
+	testGetAllowedActions(t, e, "alice", "/alice_data/resource2", nil, []string{"GET", "(GET)|(POST)"})
+	testGetAllowedActions(t, e, "cathy", "/cathy_data", nil, []string{"GET", "POST", "(GET)|(POST)"})
+	_, _ = e.AddPolicy("bob", "/bob_data/*", "^(GET|DELETE)$")
+	testGetAllowedActions(t, e, "bob", "/bob_data/1", nil, []string{"GET", "POST", "(GET)|(POST)"})
+
+	e.EnableEnforce(false)
+	testGetAllowedActions(t, e, "bob", "/bob_data/1", nil, []string{"GET", "POST", "(GET)|(POST)", "^(GET|DELETE)$"})
+
+	e, _ = NewEnforcer("examples/rbac_with_domains_model.conf", "examples/rbac_with_domains_policy.csv")
+	testGetAllowedActions(t, e, "alice", "data1", []string{"domain1"}, []string{"read", "write"})
+	testGetAllowedActions(t, e, "alice", "data2", []string{"domain2"}, []string{})
+	if _, err := e.GetAllowedActions("alice", "data1"); err == nil {
+		t.Error("a domain should be required")
+	}
+}
+
+func TestGetNamedAllowedActions(t *testing.T) {
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+r2 = sub, act
+
+[policy_definition]
+p = sub, obj, act
+p2 = sub, act, eft
+
+[policy_effect]
+e = some(where (p.eft == allow))
+e2 = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+m2 = r2.sub == p2.sub && keyMatch(r2.act, p2.act)
+`)
+	e, _ := NewEnforcer(m)
+	_, _ = e.AddPolicy("alice", "data1", "read")
+	_, _ = e.AddNamedPolicy("p2", "alice", "read", "allow")
+	_, _ = e.AddNamedPolicy("p2", "alice", "write", "allow")
+	_, _ = e.AddNamedPolicy("p2", "bob", "*", "allow")
+	_, _ = e.AddNamedPolicy("p2", "bob", "write", "deny")
+
+	res, err := e.GetNamedAllowedActions(NewEnforceContext("2"), "alice", "")
+	if err != nil {
+		t.Fatalf("GetNamedAllowedActions Error: %s", err)
+	}
+	if !util.ArrayEquals(res, []string{"read", "write"}) {
+		t.Errorf("GetNamedAllowedActions: %v", res)
+	}
+	// the action "*" is allowed by keyMatch(r2.act, p2.act) with the rule of "*"
+	res, _ = e.GetNamedAllowedActions(NewEnforceContext("2"), "bob", "")
+	if !util.ArrayEquals(res, []string{"read", "*"}) {
+		t.Errorf("GetNamedAllowedActions: %v", res)
+	}
+	res, _ = e.FilterNamedAllowed(NewEnforceContext("2"), "bob", "read", []string{""})
+	if !util.ArrayEquals(res, []string{""}) {
+		t.Errorf("FilterNamedAllowed: %v", res)
+	}
+	testGetAllowedActions(t, e, "alice", "data1", nil, []string{"read"})
+}
+
+func TestFilterAllowed(t *testing.T) {
+	e, _ := NewEnforcer("examples/keymatch_model.conf", "examples/keymatch_policy.csv")
+
+	objects := []string{"/bob_data/1", "/alice_data/resource1", "/alice_data/resource2", "/cathy_data"}
+	res, err := e.FilterAllowed("alice", "GET", objects)
+	if err != nil {
+		t.Fatalf("FilterAllowed Error: %s", err)
+	}
+	if !util.ArrayEquals(res, []string{"/alice_data/resource1", "/alice_data/resource2"}) {
+		t.Errorf("FilterAllowed: %v", res)
+	}
+
+	res, _ = e.FilterAllowed("bob", "POST", objects)
+	if !util.ArrayEquals(res, []string{"/bob_data/1"}) {
+		t.Errorf("FilterAllowed: %v", res)
+	}
+
+	se, _ := NewSyncedEnforcer("examples/rbac_with_domains_model.conf", "examples/rbac_with_domains_policy.csv")
+	res, _ = se.FilterAllowed("bob", "read", []string{"data1", "data2"}, "domain2")
+	if !util.ArrayEquals(res, []string{"data2"}) {
+		t.Errorf("FilterAllowed: %v", res)
+	}
+}
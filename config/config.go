@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -63,11 +64,12 @@ func NewConfig(confName string) (ConfigInterface, error) {
 }
 
 // NewConfigFromText create an empty configuration representation from text.
+// The include directives are only allowed in the files read by NewConfig.
 func NewConfigFromText(text string) (ConfigInterface, error) {
 	c := &Config{
 		data: make(map[string]map[string]string),
 	}
-	err := c.parseBuffer(bufio.NewReader(strings.NewReader(text)))
+	err := c.parseBuffer(bufio.NewReader(strings.NewReader(text)), &source{}, "")
 	return c, err
 }
 
@@ -87,7 +89,43 @@ func (c *Config) AddConfig(section string, option string, value string) bool {
 	return !ok
 }
 
+// source is the file or the text being parsed.
+type source struct {
+	// name is the name of the file, or "" for a text.
+	name string
+	// includes holds the absolute paths of the files being parsed, from the outermost one.
+	includes []string
+}
+
+// included returns whether the source is a file included by another one.
+func (src *source) included() bool {
+	return len(src.includes) > 1
+}
+
+// location returns the location of a line of the source for the error messages.
+func (src *source) location(lineNum int) string {
+	if src.name == "" {
+		return fmt.Sprintf("line %d", lineNum)
+	}
+	return fmt.Sprintf("%s:%d", src.name, lineNum)
+}
+
 func (c *Config) parse(fname string) (err error) {
+	return c.parseFile(fname, &source{}, "")
+}
+
+// parseFile parses the file fname included by src into section.
+func (c *Config) parseFile(fname string, src *source, section string) error {
+	path, err := filepath.Abs(fname)
+	if err != nil {
+		return err
+	}
+	for _, include := range src.includes {
+		if include == path {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(src.includes, " -> "), path)
+		}
+	}
+
 	f, err := os.Open(fname)
 	if err != nil {
 		return err
@@ -95,17 +133,60 @@ func (c *Config) parse(fname string) (err error) {
 	defer f.Close()
 
 	buf := bufio.NewReader(f)
-	return c.parseBuffer(buf)
+	return c.parseBuffer(buf, &source{name: fname, includes: append(append([]string(nil), src.includes...), path)}, section)
 }
 
-func (c *Config) parseBuffer(buf *bufio.Reader) error {
-	var section string
+// parseInclude parses the file of an include directive, e.g. include "${CONF_DIR}/roles.conf".
+// The environment variables of the path are expanded, and a relative path is relative to the including file.
+// The lines of the file before its first section belong to section.
+func (c *Config) parseInclude(directive []byte, src *source, lineNum int, section string) error {
+	path := strings.TrimSpace(string(directive[len("include"):]))
+	if len(path) >= 2 && path[0] == '"' && path[len(path)-1] == '"' {
+		path = path[1 : len(path)-1]
+	}
+
+	var undefined []string
+	path = os.Expand(path, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			undefined = append(undefined, name)
+		}
+		return value
+	})
+	if len(undefined) > 0 {
+		return fmt.Errorf("%s: undefined environment variable %s in include", src.location(lineNum), strings.Join(undefined, ", "))
+	}
+	if path == "" {
+		return fmt.Errorf("%s: include without a file", src.location(lineNum))
+	}
+
+	if !filepath.IsAbs(path) && src.name != "" {
+		path = filepath.Join(filepath.Dir(src.name), path)
+	}
+	if err := c.parseFile(path, src, section); err != nil {
+		return fmt.Errorf("%s: %w", src.location(lineNum), err)
+	}
+	return nil
+}
+
+// isInclude returns whether line is an include directive, e.g. include "roles.conf".
+func isInclude(line []byte) bool {
+	if !bytes.HasPrefix(line, []byte("include")) || len(line) == len("include") {
+		return false
+	}
+	if line[len("include")] != ' ' && line[len("include")] != '\t' {
+		return false
+	}
+	return !bytes.HasPrefix(bytes.TrimSpace(line[len("include"):]), []byte{'='})
+}
+
+func (c *Config) parseBuffer(buf *bufio.Reader, src *source, section string) error {
 	var lineNum int
 	var buffer bytes.Buffer
 	var canWrite bool
 	for {
 		if canWrite {
-			if err := c.write(section, lineNum, &buffer); err != nil {
+			if err := c.write(section, src, lineNum, &buffer); err != nil {
 				return err
 			} else {
 				canWrite = false
@@ -116,7 +197,7 @@ func (c *Config) parseBuffer(buf *bufio.Reader) error {
 		if err == io.EOF {
 			// force write when buffer is not flushed yet
 			if buffer.Len() > 0 {
-				if err = c.write(section, lineNum, &buffer); err != nil {
+				if err = c.write(section, src, lineNum, &buffer); err != nil {
 					return err
 				}
 			}
@@ -131,10 +212,18 @@ func (c *Config) parseBuffer(buf *bufio.Reader) error {
 			bytes.HasPrefix(line, DEFAULT_COMMENT):
 			canWrite = true
 			continue
+		case buffer.Len() == 0 && isInclude(line):
+			if src.name == "" {
+				// a text may come from outside the process, it must not read its files
+				return fmt.Errorf("%s: include is only allowed in a configuration file", src.location(lineNum))
+			}
+			if err := c.parseInclude(line, src, lineNum, section); err != nil {
+				return err
+			}
 		case bytes.HasPrefix(line, []byte{'['}) && bytes.HasSuffix(line, []byte{']'}):
 			// force write when buffer is not flushed yet
 			if buffer.Len() > 0 {
-				if err := c.write(section, lineNum, &buffer); err != nil {
+				if err := c.write(section, src, lineNum, &buffer); err != nil {
 					return err
 				}
 				canWrite = false
@@ -166,14 +255,18 @@ func (c *Config) parseBuffer(buf *bufio.Reader) error {
 	return nil
 }
 
-func (c *Config) write(section string, lineNum int, b *bytes.Buffer) error {
+func (c *Config) write(section string, src *source, lineNum int, b *bytes.Buffer) error {
 	if b.Len() <= 0 {
 		return nil
 	}
 
 	optionVal := bytes.SplitN(b.Bytes(), []byte{'='}, 2)
 	if len(optionVal) != 2 {
-		return fmt.Errorf("parse the content error : line %d , %s = ? ", lineNum, optionVal[0])
+		if src.included() {
+			// the content of an included file is not shown, it may not be a configuration file
+			return fmt.Errorf("parse the content error : %s", src.location(lineNum))
+		}
+		return fmt.Errorf("parse the content error : %s , %s = ? ", src.location(lineNum), optionVal[0])
 	}
 	option := bytes.TrimSpace(optionVal[0])
 	value := bytes.TrimSpace(optionVal[1])
@@ -15,6 +15,8 @@
 package config
 
 import (
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -88,3 +90,69 @@ func TestGet(t *testing.T) {
 		t.Errorf("Get failure: expected different value for multi5::name (expected: [%#v] got: [%#v])", "r.sub==p.sub&&r.obj==p.obj", v)
 	}
 }
+
+func TestInclude(t *testing.T) {
+	config, err := NewConfig("testdata/include_model.conf")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	tests := map[string]string{
+		"request_definition::r": "sub, obj, act",
+		"policy_definition::p":  "sub, obj, act",
+		"role_definition::g":    "_, _",
+		"role_definition::g2":   "_, _",
+		"policy_effect::e":      "some(where (p.eft == allow))",
+		"matchers::m":           "g(r.sub, p.sub) && g2(r.obj, p.obj) && r.act == p.act",
+	}
+	for key, expected := range tests {
+		if v := config.String(key); v != expected {
+			t.Errorf("Get failure: expected different value for %s (expected: [%#v] got: [%#v])", key, expected, v)
+		}
+	}
+	// the includer's section is restored after an include
+	if v := config.String("role_definition::e"); v != "" {
+		t.Errorf("Get failure: expected different value for role_definition::e (expected: [%#v] got: [%#v])", "", v)
+	}
+}
+
+func TestIncludeEnv(t *testing.T) {
+	_ = os.Setenv("CASBIN_TEST_INCLUDE_DIR", "include")
+	defer os.Unsetenv("CASBIN_TEST_INCLUDE_DIR")
+
+	config, err := NewConfig("testdata/include_env_model.conf")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v := config.String("role_definition::g2"); v != "_, _" {
+		t.Errorf("Get failure: expected different value for role_definition::g2 (expected: [%#v] got: [%#v])", "_, _", v)
+	}
+
+	_ = os.Unsetenv("CASBIN_TEST_INCLUDE_DIR")
+	_, err = NewConfig("testdata/include_env_model.conf")
+	if err == nil || !strings.Contains(err.Error(), "testdata/include_env_model.conf:4: undefined environment variable CASBIN_TEST_INCLUDE_DIR") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	_, err := NewConfig("testdata/include_cycle_a.conf")
+	if err == nil || !strings.Contains(err.Error(), "include cycle") || !strings.HasPrefix(err.Error(), "testdata/include_cycle_a.conf:4: ") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = NewConfig("testdata/include_error_model.conf")
+	if err == nil || err.Error() != "testdata/include_error_model.conf:4: parse the content error : testdata/include/invalid.conf:3" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = NewConfigFromText("[request_definition]\nr = sub, obj, act\ninclude testdata/include/roles.conf")
+	if err == nil || err.Error() != "line 3: include is only allowed in a configuration file" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = NewConfigFromText("[request_definition]\nr = sub, obj, act\ng2")
+	if err == nil || err.Error() != "parse the content error : line 3 , g2 = ? " {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -15,11 +15,14 @@
 package constant
 
 const (
-	ActionIndex   = "act"
-	DomainIndex   = "dom"
-	SubjectIndex  = "sub"
-	ObjectIndex   = "obj"
-	PriorityIndex = "priority"
+	ActionIndex     = "act"
+	DomainIndex     = "dom"
+	SubjectIndex    = "sub"
+	ObjectIndex     = "obj"
+	PriorityIndex   = "priority"
+	ObligationIndex = "obligation"
+	AdviceIndex     = "advice"
+	MessageIndex    = "message"
 )
 
 const (
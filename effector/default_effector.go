@@ -31,21 +31,26 @@ func NewDefaultEffector() *DefaultEffector {
 }
 
 // MergeEffects merges all matching results collected by the enforcer into a single decision.
+// The step-up effects Challenge and Audit are merged with a precedence depending on expr:
+// under allow-override the most permissive effect applies (Allow > Audit > Challenge),
+// under deny-override and allow-and-deny the most restrictive one applies (Deny > Challenge > Audit > Allow),
+// under priority the effect of the matched rule with the highest priority applies.
 func (e *DefaultEffector) MergeEffects(expr string, effects []Effect, matches []float64, policyIndex int, policyLength int) (Effect, int, error) {
 	result := Indeterminate
 	explainIndex := -1
 
 	switch expr {
 	case constant.AllowOverrideEffect:
-		if matches[policyIndex] == 0 {
-			break
-		}
 		// only check the current policyIndex
-		if effects[policyIndex] == Allow {
+		if matches[policyIndex] != 0 && effects[policyIndex] == Allow {
 			result = Allow
 			explainIndex = policyIndex
 			break
 		}
+		// if no allow rules are matched at last, then the most permissive step-up effect applies
+		if policyIndex == policyLength-1 {
+			result, explainIndex = mergeStepUpEffects(effects, matches, Audit, Challenge)
+		}
 	case constant.DenyOverrideEffect:
 		// only check the current policyIndex
 		if matches[policyIndex] != 0 && effects[policyIndex] == Deny {
@@ -53,9 +58,12 @@ func (e *DefaultEffector) MergeEffects(expr string, effects []Effect, matches []
 			explainIndex = policyIndex
 			break
 		}
-		// if no deny rules are matched  at last, then allow
+		// if no deny rules are matched at last, then the most restrictive step-up effect applies, or allow
 		if policyIndex == policyLength-1 {
-			result = Allow
+			result, explainIndex = mergeStepUpEffects(effects, matches, Challenge, Audit)
+			if result == Indeterminate {
+				result = Allow
+			}
 		}
 	case constant.AllowAndDenyEffect:
 		// short-circuit if matched deny rule
@@ -71,19 +79,9 @@ func (e *DefaultEffector) MergeEffects(expr string, effects []Effect, matches []
 			// choose not to short-circuit
 			return result, explainIndex, nil
 		}
-		// merge all effects at last
-		for i, eft := range effects {
-			if matches[i] == 0 {
-				continue
-			}
-
-			if eft == Allow {
-				result = Allow
-				// set hit rule to first matched allow rule
-				explainIndex = i
-				break
-			}
-		}
+		// merge all effects at last, the most restrictive step-up effect applies before allow
+		// set hit rule to first matched rule of the effect
+		result, explainIndex = mergeStepUpEffects(effects, matches, Challenge, Audit, Allow)
 	case constant.PriorityEffect, constant.SubjectPriorityEffect:
 		// reverse merge, short-circuit may be earlier
 		for i := len(effects) - 1; i >= 0; i-- {
@@ -92,11 +90,7 @@ func (e *DefaultEffector) MergeEffects(expr string, effects []Effect, matches []
 			}
 
 			if effects[i] != Indeterminate {
-				if effects[i] == Allow {
-					result = Allow
-				} else {
-					result = Deny
-				}
+				result = effects[i]
 				explainIndex = i
 				break
 			}
@@ -107,3 +101,40 @@ func (e *DefaultEffector) MergeEffects(expr string, effects []Effect, matches []
 
 	return result, explainIndex, nil
 }
+
+// mergeStepUpEffects returns the first of the effects in precedence order that is the effect of a matched rule,
+// and the index of the first matched rule having it.
+func mergeStepUpEffects(effects []Effect, matches []float64, precedence ...Effect) (Effect, int) {
+	for _, effect := range precedence {
+		for i, eft := range effects {
+			if matches[i] != 0 && eft == effect {
+				return effect, i
+			}
+		}
+	}
+	return Indeterminate, -1
+}
+
+// ApplicableRules returns the indexes of the matched rules whose obligations apply to the merged result.
+// With priority effects only the deciding rule applies, otherwise all the matched rules having the effect of the result apply.
+func (e *DefaultEffector) ApplicableRules(expr string, effects []Effect, matches []float64, result Effect, explainIndex int) []int {
+	if result == Indeterminate {
+		return nil
+	}
+
+	switch expr {
+	case constant.AllowOverrideEffect, constant.DenyOverrideEffect, constant.AllowAndDenyEffect:
+		var indexes []int
+		for i, eft := range effects {
+			if matches[i] != 0 && eft == result {
+				indexes = append(indexes, i)
+			}
+		}
+		return indexes
+	default:
+		if explainIndex == -1 {
+			return nil
+		}
+		return []int{explainIndex}
+	}
+}
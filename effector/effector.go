@@ -22,10 +22,36 @@ const (
 	Allow Effect = iota
 	Indeterminate
 	Deny
+	// Challenge allows the request once the subject has re-authenticated, e.g. with MFA.
+	Challenge
+	// Audit allows the request, which must be recorded.
+	Audit
 )
 
+// String returns the name of the effect as written in the "eft" field of the policy.
+func (e Effect) String() string {
+	switch e {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	case Challenge:
+		return "challenge"
+	case Audit:
+		return "audit"
+	default:
+		return "indeterminate"
+	}
+}
+
 // Effector is the interface for Casbin effectors.
 type Effector interface {
 	// MergeEffects merges all matching results collected by the enforcer into a single decision.
 	MergeEffects(expr string, effects []Effect, matches []float64, policyIndex int, policyLength int) (Effect, int, error)
 }
+
+// ObligationEffector is implemented by effectors that decide which matched rules' obligations apply to a decision.
+type ObligationEffector interface {
+	// ApplicableRules returns the indexes of the matched rules whose obligations apply to the merged result.
+	ApplicableRules(expr string, effects []Effect, matches []float64, result Effect, explainIndex int) []int
+}
@@ -15,11 +15,15 @@
 package casbin
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/casbin/casbin/v3/detector"
 	"github.com/casbin/casbin/v3/effector"
@@ -43,6 +47,7 @@ type Enforcer struct {
 
 	adapter    persist.Adapter
 	watcher    persist.Watcher
+	instanceID string
 	dispatcher persist.Dispatcher
 	rmMap      map[string]rbac.RoleManager
 	condRmMap  map[string]rbac.ConditionalRoleManager
@@ -58,6 +63,13 @@ type Enforcer struct {
 	acceptJsonRequest    bool
 	gFunctionCache       bool
 
+	matcherErrorPolicy MatcherErrorPolicy
+	faultyRuleCount    uint64
+
+	// evalExpressions caches the compiled eval() rules by content, it is invalidated with matcherMap.
+	evalExpressions *sync.Map
+	evalPolicies    map[string]*EvalPolicy
+
 	aiConfig AIConfig
 }
 
@@ -187,7 +199,11 @@ func (e *Enforcer) initialize() {
 	e.condRmMap = map[string]rbac.ConditionalRoleManager{}
 	e.eft = effector.NewDefaultEffector()
 	e.watcher = nil
+	if e.instanceID == "" {
+		e.instanceID = newInstanceID()
+	}
 	e.matcherMap = sync.Map{}
+	e.evalExpressions = &sync.Map{}
 
 	e.enabled = true
 	e.autoSave = true
@@ -243,16 +259,45 @@ func (e *Enforcer) SetAdapter(adapter persist.Adapter) {
 	e.adapter = adapter
 }
 
-// SetWatcher sets the current watcher.
+// SetWatcher sets the current watcher, with the update callback returned by NewWatcherCallback.
+// The callback of a persist.WatcherEx is left as set by the caller, unless it is a persist.InstanceWatcher.
 func (e *Enforcer) SetWatcher(watcher persist.Watcher) error {
-	e.watcher = watcher
-	if _, ok := e.watcher.(persist.WatcherEx); ok {
-		// The callback of WatcherEx has no generic implementation.
+	e.setWatcher(watcher)
+	if !setsWatcherCallback(watcher) {
 		return nil
-	} else {
-		// In case the Watcher wants to use a customized callback function, call `SetUpdateCallback` after `SetWatcher`.
-		return watcher.SetUpdateCallback(func(string) { _ = e.LoadPolicy() })
 	}
+	// In case the Watcher wants to use a customized callback function, call `SetUpdateCallback` after `SetWatcher`.
+	return watcher.SetUpdateCallback(NewWatcherCallback(e))
+}
+
+// setWatcher sets the current watcher, and gives it the instance ID if it is a persist.InstanceWatcher.
+func (e *Enforcer) setWatcher(watcher persist.Watcher) {
+	e.watcher = watcher
+	if w, ok := watcher.(persist.InstanceWatcher); ok {
+		w.SetInstanceID(e.instanceID)
+	}
+}
+
+// GetInstanceID gets the instance ID of the enforcer, a random ID by default,
+// which identifies its messages sent by a persist.InstanceWatcher.
+func (e *Enforcer) GetInstanceID() string {
+	return e.instanceID
+}
+
+// SetInstanceID sets the instance ID of the enforcer, which should be unique among the enforcers sharing a watcher channel.
+func (e *Enforcer) SetInstanceID(id string) {
+	e.instanceID = id
+	if w, ok := e.watcher.(persist.InstanceWatcher); ok {
+		w.SetInstanceID(id)
+	}
+}
+
+func newInstanceID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
 }
 
 // GetRoleManager gets the current role manager.
@@ -395,6 +440,10 @@ func (e *Enforcer) loadPolicyFromAdapter(baseModel model.Model) (model.Model, er
 		return nil, err
 	}
 
+	if err := newModel.ValidatePolicySchemas(); err != nil {
+		return nil, err
+	}
+
 	if err := newModel.SortPoliciesBySubjectHierarchy(); err != nil {
 		return nil, err
 	}
@@ -431,6 +480,7 @@ func (e *Enforcer) applyModifiedModel(newModel model.Model) error {
 
 	e.model = newModel
 	e.invalidateMatcherMap()
+	e.compileAllEvalRules()
 	return nil
 }
 
@@ -485,6 +535,10 @@ func (e *Enforcer) loadFilteredPolicy(filter interface{}) error {
 		return err
 	}
 
+	if err := e.model.ValidatePolicySchemas(); err != nil {
+		return err
+	}
+
 	if err := e.model.SortPoliciesBySubjectHierarchy(); err != nil {
 		return err
 	}
@@ -687,10 +741,12 @@ func NewEnforceContext(suffix string) EnforceContext {
 
 func (e *Enforcer) invalidateMatcherMap() {
 	e.matcherMap = sync.Map{}
+	e.evalExpressions = &sync.Map{}
 }
 
 // enforce use a custom matcher to decides whether a "subject" can access a "object" with the operation "action", input parameters are usually: (matcher, sub, obj, act), use model matcher by default when matcher is "".
-func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interface{}) (ok bool, err error) { //nolint:funlen,cyclop,gocyclo // TODO: reduce function complexity
+// ctx is passed to the context-aware functions and cancels the policy scan when it is done.
+func (e *Enforcer) enforce(ctx context.Context, matcher string, explains *[]string, rvals ...interface{}) (ok bool, err error) { //nolint:funlen,cyclop,gocyclo // TODO: reduce function complexity
 	logEntry := e.onLogBeforeEventInEnforce(rvals)
 
 	defer func() {
@@ -700,26 +756,19 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 				logEntry.Error = err
 			}
 		}
+		if err != nil && ctx.Err() != nil && e.logger != nil && logEntry != nil {
+			logEntry.Canceled = true
+			logEntry.Error = err
+		}
 		e.onLogAfterEventInEnforce(logEntry, ok)
 	}()
 
-	if !e.enabled {
-		return true, nil
+	if err = ctx.Err(); err != nil {
+		return false, err
 	}
 
-	functions := e.fm.GetFunctions()
-	if _, ok := e.model["g"]; ok {
-		for key, ast := range e.model["g"] {
-			// g must be a normal role definition (ast.RM != nil)
-			//   or a conditional role definition (ast.CondRM != nil)
-			// ast.RM and ast.CondRM shouldn't be nil at the same time
-			if ast.RM != nil {
-				functions[key] = util.GenerateGFunction(ast.RM, e.gFunctionCache)
-			}
-			if ast.CondRM != nil {
-				functions[key] = util.GenerateConditionalGFunction(ast.CondRM)
-			}
-		}
+	if !e.enabled {
+		return true, nil
 	}
 
 	var (
@@ -727,19 +776,63 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 		pType = "p"
 		eType = "e"
 		mType = "m"
+
+		requestRoles RequestRoles
+		batch        *batchEnforceState
+		obligations  *Obligations
+		outcome      *enforceOutcome
+		reason       *enforceReason
 	)
-	if len(rvals) != 0 {
-		switch rvals[0].(type) {
-		case EnforceContext:
-			enforceContext := rvals[0].(EnforceContext)
+	for len(rvals) != 0 {
+		if enforceContext, ok := rvals[0].(EnforceContext); ok {
 			rType = enforceContext.RType
 			pType = enforceContext.PType
 			eType = enforceContext.EType
 			mType = enforceContext.MType
-			rvals = rvals[1:]
-		default:
+		} else if roles, ok := rvals[0].(RequestRoles); ok {
+			requestRoles = append(requestRoles, roles...)
+		} else if state, ok := rvals[0].(*batchEnforceState); ok {
+			batch = state
+		} else if o, ok := rvals[0].(*Obligations); ok {
+			obligations = o
+		} else if o, ok := rvals[0].(*enforceOutcome); ok {
+			outcome = o
+		} else if r, ok := rvals[0].(*enforceReason); ok {
+			reason = r
+		} else {
 			break
 		}
+		rvals = rvals[1:]
+	}
+
+	rvals, err = resolveNamedRequest(e.model["r"][rType].Tokens, rType, rvals)
+	if err != nil {
+		return false, err
+	}
+
+	functions := e.fm.GetFunctions()
+	if _, ok := e.model["g"]; ok {
+		for key, ast := range e.model["g"] {
+			// g must be a normal role definition (ast.RM != nil)
+			//   or a conditional role definition (ast.CondRM != nil)
+			// ast.RM and ast.CondRM shouldn't be nil at the same time
+			if batch != nil && e.gFunctionCache {
+				if function := batch.getGFunction(key, ast); function != nil {
+					functions[key] = function
+				}
+			} else if function := e.generateGFunction(ast); function != nil {
+				functions[key] = function
+			}
+			if len(requestRoles) != 0 && functions[key] != nil {
+				functions[key] = requestRoles.generateGFunction(key, functions[key])
+			}
+		}
+	}
+
+	// The context functions get ctx from the parameters of the request, see passEvalParameters.
+	ctxFunctions := e.fm.GetContextFunctions()
+	for name, function := range ctxFunctions {
+		functions[name] = generateContextFunction(name, function)
 	}
 
 	var expString string
@@ -779,18 +872,34 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 	}
 
 	parameters := enforceParameters{
+		ctx: ctx,
+
 		rTokens: rTokens,
 		rVals:   rvals,
 
 		pTokens: pTokens,
 	}
 
+	// The functions bound to the expression depend on the request roles, so it cannot be shared.
+	noCache := len(requestRoles) != 0
 	hasEval := util.HasEval(expString)
 	if hasEval {
-		functions["eval"] = generateEvalFunction(functions, &parameters)
+		evalExpressions := e.evalExpressions
+		if noCache {
+			evalExpressions = nil
+		}
+		functions["eval"] = generateEvalFunction(functions, ctxFunctions, evalExpressions, pType, e.evalPolicies[pType])
+	}
+	if hasEval || len(ctxFunctions) != 0 {
+		expString = passEvalParameters(expString, ctxFunctions)
 	}
 	var expression *govaluate.EvaluableExpression
-	expression, err = e.getAndStoreMatcherExpression(hasEval, expString, functions)
+	if batch != nil && !hasEval && len(requestRoles) == 0 {
+		// The expression is shared by the requests of the batch.
+		expression, err = batch.getExpression(expString, functions)
+	} else {
+		expression, err = e.getAndStoreMatcherExpression(noCache, expString, functions)
+	}
 	if err != nil {
 		return false, err
 	}
@@ -808,13 +917,23 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 
 	var effect effector.Effect
 	var explainIndex int
+	// the index of the deciding rule, -1 when the matcher does not use the policy
+	ruleIndex := -1
 
 	if policyLen := len(e.model["p"][pType].Policy); policyLen != 0 && strings.Contains(expString, pType+"_") { //nolint:nestif // TODO: reduce function complexity
 		policyEffects = make([]effector.Effect, policyLen)
 		matcherResults = make([]float64, policyLen)
+		decided := false
 
 		for policyIndex, pvals := range e.model["p"][pType].Policy {
+			if err = ctx.Err(); err != nil {
+				return false, err
+			}
 			// log.LogPrint("Policy Rule: ", pvals)
+			if len(e.model["p"][pType].Tokens) != len(pvals) {
+				// the rule may have been loaded without its optional fields
+				pvals = e.model.FillPolicyDefaults("p", pType, pvals)
+			}
 			if len(e.model["p"][pType].Tokens) != len(pvals) {
 				return false, fmt.Errorf(
 					"invalid policy size: expected %d, got %d, pvals: %v",
@@ -828,32 +947,44 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 			result, err := expression.Eval(parameters)
 			// log.LogPrint("Result: ", result)
 
-			if err != nil {
-				return false, err
-			}
-
 			// set to no-match at first
 			matcherResults[policyIndex] = 0
-			switch result := result.(type) {
-			case bool:
-				if result {
-					matcherResults[policyIndex] = 1
+			if err == nil {
+				switch result := result.(type) {
+				case bool:
+					if result {
+						matcherResults[policyIndex] = 1
+					}
+				case float64:
+					if result != 0 {
+						matcherResults[policyIndex] = 1
+					}
+				default:
+					err = errors.New("matcher result should be bool, int or float")
 				}
-			case float64:
-				if result != 0 {
-					matcherResults[policyIndex] = 1
+			}
+
+			if err != nil {
+				if e.matcherErrorPolicy == MatcherErrorAbort || ctx.Err() != nil {
+					return false, err
 				}
-			default:
-				return false, errors.New("matcher result should be bool, int or float")
+				e.reportFaultyRule(logEntry, pvals)
 			}
 
-			if j, ok := parameters.pTokens[pType+"_eft"]; ok {
-				eft := parameters.pVals[j]
-				if eft == "allow" {
+			if err != nil && e.matcherErrorPolicy == MatcherErrorDeny {
+				matcherResults[policyIndex] = 1
+				policyEffects[policyIndex] = effector.Deny
+			} else if j, ok := parameters.pTokens[pType+"_eft"]; ok {
+				switch parameters.pVals[j] {
+				case "allow":
 					policyEffects[policyIndex] = effector.Allow
-				} else if eft == "deny" {
+				case "deny":
 					policyEffects[policyIndex] = effector.Deny
-				} else {
+				case "challenge":
+					policyEffects[policyIndex] = effector.Challenge
+				case "audit":
+					policyEffects[policyIndex] = effector.Audit
+				default:
 					policyEffects[policyIndex] = effector.Indeterminate
 				}
 			} else {
@@ -864,14 +995,29 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 			//	break
 			// }
 
+			// The remaining rules are only matched to collect their obligations once the decision is made.
+			if decided {
+				continue
+			}
+
 			effect, explainIndex, err = e.eft.MergeEffects(e.model["e"][eType].Value, policyEffects, matcherResults, policyIndex, policyLen)
 			if err != nil {
 				return false, err
 			}
 			if effect != effector.Indeterminate {
-				break
+				if obligations == nil {
+					break
+				}
+				decided = true
 			}
 		}
+
+		ruleIndex = explainIndex
+
+		if obligations != nil {
+			obligations.collect(e.eft, e.model["e"][eType].Value, e.model["p"][pType].Tokens, pType, e.model["p"][pType].Policy,
+				policyEffects, matcherResults, effect, explainIndex)
+		}
 	} else {
 		if hasEval && len(e.model["p"][pType].Policy) == 0 {
 			return false, errors.New("please make sure rule exists in policy when using eval() in matcher")
@@ -907,53 +1053,89 @@ func (e *Enforcer) enforce(matcher string, explains *[]string, rvals ...interfac
 		}
 	}
 
-	// effect -> result
+	if outcome != nil {
+		outcome.effect = effect
+	}
+
+	// effect -> result, a challenge must be handled by the caller so it is not allowed
 	result := false
-	if effect == effector.Allow {
+	if effect == effector.Allow || effect == effector.Audit {
 		result = true
 	}
 
+	if reason != nil {
+		reason.reason = e.getEnforceReason(expression, parameters, rType, pType, result, ruleIndex)
+	}
+
 	return result, nil
 }
 
-func (e *Enforcer) getAndStoreMatcherExpression(hasEval bool, expString string, functions map[string]govaluate.ExpressionFunction) (*govaluate.EvaluableExpression, error) {
+func (e *Enforcer) getAndStoreMatcherExpression(noCache bool, expString string, functions map[string]govaluate.ExpressionFunction) (*govaluate.EvaluableExpression, error) {
 	var expression *govaluate.EvaluableExpression
 	var err error
 	var cachedExpression, isPresent = e.matcherMap.Load(expString)
 
-	if !hasEval && isPresent {
+	if !noCache && isPresent {
 		expression = cachedExpression.(*govaluate.EvaluableExpression)
 	} else {
 		expression, err = govaluate.NewEvaluableExpressionWithFunctions(expString, functions)
 		if err != nil {
 			return nil, err
 		}
-		e.matcherMap.Store(expString, expression)
+		if !noCache {
+			e.matcherMap.Store(expString, expression)
+		}
 	}
 	return expression, nil
 }
 
 // Enforce decides whether a "subject" can access a "object" with the operation "action", input parameters are usually: (sub, obj, act).
 func (e *Enforcer) Enforce(rvals ...interface{}) (bool, error) {
-	return e.enforce("", nil, rvals...)
+	return e.enforce(context.Background(), "", nil, rvals...)
+}
+
+// EnforceWithRoles decides whether a "subject" can access a "object" with the operation "action",
+// taking into account the roles asserted for this request only, e.g. by an identity token.
+// The roles are consulted by g() in addition to the role manager and are never persisted.
+func (e *Enforcer) EnforceWithRoles(roles RequestRoles, rvals ...interface{}) (bool, error) {
+	return e.enforce(context.Background(), "", nil, append([]interface{}{roles}, rvals...)...)
 }
 
 // EnforceWithMatcher use a custom matcher to decides whether a "subject" can access a "object" with the operation "action", input parameters are usually: (matcher, sub, obj, act), use model matcher by default when matcher is "".
 func (e *Enforcer) EnforceWithMatcher(matcher string, rvals ...interface{}) (bool, error) {
-	return e.enforce(matcher, nil, rvals...)
+	return e.enforce(context.Background(), matcher, nil, rvals...)
+}
+
+// enforceOutcome receives the merged effect of an enforcement.
+type enforceOutcome struct {
+	effect effector.Effect
+}
+
+// EnforceWithEffect decides whether a "subject" can access a "object" with the operation "action" and returns the
+// merged effect, so that step-up effects can be handled: effector.Challenge asks the subject to re-authenticate
+// and effector.Audit allows the request which must be recorded.
+// Enforce returns true for effector.Allow and effector.Audit.
+func (e *Enforcer) EnforceWithEffect(rvals ...interface{}) (effector.Effect, error) {
+	// the effect is allow when the enforcer is disabled
+	outcome := &enforceOutcome{effect: effector.Allow}
+	_, err := e.enforce(context.Background(), "", nil, append([]interface{}{outcome}, rvals...)...)
+	if err != nil {
+		return effector.Indeterminate, err
+	}
+	return outcome.effect, nil
 }
 
 // EnforceEx explain enforcement by informing matched rules.
 func (e *Enforcer) EnforceEx(rvals ...interface{}) (bool, []string, error) {
 	explain := []string{}
-	result, err := e.enforce("", &explain, rvals...)
+	result, err := e.enforce(context.Background(), "", &explain, rvals...)
 	return result, explain, err
 }
 
 // EnforceExWithMatcher use a custom matcher and explain enforcement by informing matched rules.
 func (e *Enforcer) EnforceExWithMatcher(matcher string, rvals ...interface{}) (bool, []string, error) {
 	explain := []string{}
-	result, err := e.enforce(matcher, &explain, rvals...)
+	result, err := e.enforce(context.Background(), matcher, &explain, rvals...)
 	return result, explain, err
 }
 
@@ -961,7 +1143,7 @@ func (e *Enforcer) EnforceExWithMatcher(matcher string, rvals ...interface{}) (b
 func (e *Enforcer) BatchEnforce(requests [][]interface{}) ([]bool, error) {
 	var results []bool
 	for _, request := range requests {
-		result, err := e.enforce("", nil, request...)
+		result, err := e.enforce(context.Background(), "", nil, request...)
 		if err != nil {
 			return results, err
 		}
@@ -974,7 +1156,34 @@ func (e *Enforcer) BatchEnforce(requests [][]interface{}) ([]bool, error) {
 func (e *Enforcer) BatchEnforceWithMatcher(matcher string, requests [][]interface{}) ([]bool, error) {
 	var results []bool
 	for _, request := range requests {
-		result, err := e.enforce(matcher, nil, request...)
+		result, err := e.enforce(context.Background(), matcher, nil, request...)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// EnforceWithContext decides whether a "subject" can access a "object" with the operation "action".
+// ctx is passed to the functions added with AddContextFunction, and the enforcement is aborted with ctx.Err() when ctx is done.
+func (e *Enforcer) EnforceWithContext(ctx context.Context, rvals ...interface{}) (bool, error) {
+	return e.enforce(ctx, "", nil, rvals...)
+}
+
+// EnforceExWithContext explain enforcement by informing matched rules, using ctx as EnforceWithContext does.
+func (e *Enforcer) EnforceExWithContext(ctx context.Context, rvals ...interface{}) (bool, []string, error) {
+	explain := []string{}
+	result, err := e.enforce(ctx, "", &explain, rvals...)
+	return result, explain, err
+}
+
+// BatchEnforceWithContext enforce in batches, using ctx as EnforceWithContext does.
+// The remaining requests are not evaluated once ctx is done.
+func (e *Enforcer) BatchEnforceWithContext(ctx context.Context, requests [][]interface{}) ([]bool, error) {
+	var results []bool
+	for _, request := range requests {
+		result, err := e.enforce(ctx, "", nil, request...)
 		if err != nil {
 			return results, err
 		}
@@ -1025,6 +1234,22 @@ func (e *Enforcer) AddNamedDomainLinkConditionFunc(ptype, user, role string, dom
 	return false
 }
 
+// RegisterLinkConditionFunc registers a link condition function by name for the grouping policy "g".
+// Rules such as "g, alice, admin, timeWindow, 09:00, 17:00" whose first condition column is name
+// are bound to fn, and the remaining condition columns are passed to it as parameters.
+func (e *Enforcer) RegisterLinkConditionFunc(name string, fn rbac.LinkConditionFunc) bool {
+	return e.RegisterNamedLinkConditionFunc("g", name, fn)
+}
+
+// RegisterNamedLinkConditionFunc registers a link condition function by name for the grouping policy ptype.
+// The function is bound to the rules already loaded and to those added or loaded later.
+func (e *Enforcer) RegisterNamedLinkConditionFunc(ptype, name string, fn rbac.LinkConditionFunc) bool {
+	if _, ok := e.condRmMap[ptype]; !ok {
+		return false
+	}
+	return e.model.AddLinkConditionFunc(ptype, name, fn) == nil
+}
+
 // SetNamedLinkConditionFuncParams Sets the parameters of the condition function fn for Link userName->roleName.
 func (e *Enforcer) SetNamedLinkConditionFuncParams(ptype, user, role string, params ...string) bool {
 	if rm, ok := e.condRmMap[ptype]; ok {
@@ -1046,6 +1271,9 @@ func (e *Enforcer) SetNamedDomainLinkConditionFuncParams(ptype, user, role, doma
 
 // assumes bounds have already been checked.
 type enforceParameters struct {
+	// ctx is the context of the request, passed to the context functions.
+	ctx context.Context
+
 	rTokens map[string]int
 	rVals   []interface{}
 
@@ -1058,6 +1286,9 @@ func (p enforceParameters) Get(name string) (interface{}, error) {
 	if name == "" {
 		return nil, nil
 	}
+	if name == evalParametersName {
+		return p, nil
+	}
 
 	switch name[0] {
 	case 'p':
@@ -1076,22 +1307,3 @@ func (p enforceParameters) Get(name string) (interface{}, error) {
 		return nil, errors.New("No parameter '" + name + "' found.")
 	}
 }
-
-func generateEvalFunction(functions map[string]govaluate.ExpressionFunction, parameters *enforceParameters) govaluate.ExpressionFunction {
-	return func(args ...interface{}) (interface{}, error) {
-		if len(args) != 1 {
-			return nil, fmt.Errorf("function eval(subrule string) expected %d arguments, but got %d", 1, len(args))
-		}
-
-		expression, ok := args[0].(string)
-		if !ok {
-			return nil, errors.New("argument of eval(subrule string) must be a string")
-		}
-		expression = util.EscapeAssertion(expression)
-		expr, err := govaluate.NewEvaluableExpressionWithFunctions(expression, functions)
-		if err != nil {
-			return nil, fmt.Errorf("error while parsing eval parameter: %s, %s", expression, err.Error())
-		}
-		return expr.Eval(parameters)
-	}
-}
@@ -0,0 +1,154 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/util"
+	"github.com/casbin/govaluate"
+)
+
+// batchEnforceState holds the work shared by the requests of a batch:
+// the compiled matchers and the g() functions, whose results are memoized for the duration of the batch
+// unless the g() function cache is disabled by EnableGFunctionCache.
+// It is passed to enforce as an element of the parameter "rvals" before the request values.
+type batchEnforceState struct {
+	gFunctions  map[string]govaluate.ExpressionFunction
+	expressions map[string]*govaluate.EvaluableExpression
+	mutex       sync.Mutex
+}
+
+func newBatchEnforceState() *batchEnforceState {
+	return &batchEnforceState{
+		gFunctions:  make(map[string]govaluate.ExpressionFunction),
+		expressions: make(map[string]*govaluate.EvaluableExpression),
+	}
+}
+
+// getGFunction returns the memoized g() function of ptype, nil if the assertion has no role manager.
+func (b *batchEnforceState) getGFunction(ptype string, ast *model.Assertion) govaluate.ExpressionFunction {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if function, ok := b.gFunctions[ptype]; ok {
+		return function
+	}
+
+	var function govaluate.ExpressionFunction
+	if ast.RM != nil {
+		function = util.GenerateGFunction(ast.RM, true)
+	}
+	if ast.CondRM != nil {
+		function = memoizeFunction(util.GenerateConditionalGFunction(ast.CondRM))
+	}
+	b.gFunctions[ptype] = function
+	return function
+}
+
+// getExpression returns the matcher compiled for the batch.
+func (b *batchEnforceState) getExpression(expString string, functions map[string]govaluate.ExpressionFunction) (*govaluate.EvaluableExpression, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if expression, ok := b.expressions[expString]; ok {
+		return expression, nil
+	}
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(expString, functions)
+	if err != nil {
+		return nil, err
+	}
+	b.expressions[expString] = expression
+	return expression, nil
+}
+
+// memoizeFunction caches the results of a function whose arguments are all strings.
+func memoizeFunction(function govaluate.ExpressionFunction) govaluate.ExpressionFunction {
+	memorized := sync.Map{}
+	return func(args ...interface{}) (interface{}, error) {
+		key := strings.Builder{}
+		for _, arg := range args {
+			str, ok := arg.(string)
+			if !ok {
+				return function(args...)
+			}
+			key.WriteByte(0)
+			key.WriteString(str)
+		}
+
+		if v, found := memorized.Load(key.String()); found {
+			return v, nil
+		}
+		v, err := function(args...)
+		if err != nil {
+			return nil, err
+		}
+		memorized.Store(key.String(), v)
+		return v, nil
+	}
+}
+
+// ParallelBatchEnforce enforces the requests concurrently with at most workers goroutines,
+// or runtime.NumCPU() goroutines if workers <= 0.
+// The matchers are compiled once and, unless disabled by EnableGFunctionCache,
+// the g() results are shared by all the requests of the batch.
+// Results are returned in the order of the requests, and errors[i] is the error of requests[i], if any.
+func (e *Enforcer) ParallelBatchEnforce(requests [][]interface{}, workers int) ([]bool, []error) {
+	return e.ParallelBatchEnforceWithContext(context.Background(), requests, workers)
+}
+
+// ParallelBatchEnforceWithContext is ParallelBatchEnforce using ctx as EnforceWithContext does.
+// The requests not evaluated yet when ctx is done fail with ctx.Err().
+func (e *Enforcer) ParallelBatchEnforceWithContext(ctx context.Context, requests [][]interface{}, workers int) ([]bool, []error) {
+	results := make([]bool, len(requests))
+	errs := make([]error, len(requests))
+	if len(requests) == 0 {
+		return results, errs
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(requests) {
+		workers = len(requests)
+	}
+
+	batch := newBatchEnforceState()
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				rvals := make([]interface{}, 0, len(requests[i])+1)
+				rvals = append(rvals, batch)
+				rvals = append(rvals, requests[i]...)
+				results[i], errs[i] = e.enforce(ctx, "", nil, rvals...)
+			}
+		}()
+	}
+	for i := range requests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, errs
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelBatchEnforce(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	var requests [][]interface{}
+	for _, sub := range []string{"alice", "bob", "cathy", "data2_admin"} {
+		for _, obj := range []string{"data1", "data2"} {
+			for _, act := range []string{"read", "write"} {
+				requests = append(requests, []interface{}{sub, obj, act})
+			}
+		}
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		results, errs := e.ParallelBatchEnforce(requests, workers)
+		if len(results) != len(requests) || len(errs) != len(requests) {
+			t.Fatalf("expected %d results, got %d results and %d errors", len(requests), len(results), len(errs))
+		}
+		for i, request := range requests {
+			expected, _ := e.Enforce(request...)
+			if errs[i] != nil {
+				t.Errorf("%v: unexpected error %v", request, errs[i])
+			} else if results[i] != expected {
+				t.Errorf("%v with %d workers: %t, supposed to be %t", request, workers, results[i], expected)
+			}
+		}
+	}
+
+	results, errs := e.ParallelBatchEnforce(nil, 4)
+	if len(results) != 0 || len(errs) != 0 {
+		t.Error("an empty batch should return empty results")
+	}
+}
+
+func TestParallelBatchEnforceErrors(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	requests := [][]interface{}{
+		{"alice", "data1", "read"},
+		{"alice", "data1"},
+		{"bob", "data2", "write"},
+	}
+	results, errs := e.ParallelBatchEnforce(requests, 2)
+	if errs[0] != nil || !results[0] || errs[2] != nil || !results[2] {
+		t.Errorf("valid requests should succeed: %v, %v", results, errs)
+	}
+	if errs[1] == nil {
+		t.Error("an invalid request should fail on its own")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs = e.ParallelBatchEnforceWithContext(ctx, requests, 2)
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("request %d: error = %v, supposed to be canceled", i, err)
+		}
+	}
+}
+
+func TestParallelBatchEnforceSharesGFunction(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_temporal_roles_model.conf")
+	_, _ = e.AddPolicy("data2_admin", "data2", "read")
+	_, _ = e.AddGroupingPolicy("alice", "data2_admin", "_", "_")
+
+	var calls int32
+	e.AddNamedLinkConditionFunc("g", "alice", "data2_admin", func(args ...string) (bool, error) {
+		atomic.AddInt32(&calls, 1)
+		return true, nil
+	})
+
+	requests := make([][]interface{}, 20)
+	for i := range requests {
+		requests[i] = []interface{}{"alice", "data2", "read"}
+	}
+	results, errs := e.ParallelBatchEnforce(requests, 1)
+	for i := range requests {
+		if errs[i] != nil || !results[i] {
+			t.Fatalf("request %d: %t, %v", i, results[i], errs[i])
+		}
+	}
+	if calls != 1 {
+		t.Errorf("the link condition was evaluated %d times, supposed to be once per batch", calls)
+	}
+
+	e.EnableGFunctionCache(false)
+	calls = 0
+	results, errs = e.ParallelBatchEnforce(requests, 1)
+	for i := range requests {
+		if errs[i] != nil || !results[i] {
+			t.Fatalf("request %d: %t, %v", i, results[i], errs[i])
+		}
+	}
+	if calls != int32(len(requests)) {
+		t.Errorf("the link condition was evaluated %d times, supposed to be once per request without g() cache", calls)
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/casbin/casbin/v3/persist"
 	"github.com/casbin/casbin/v3/persist/cache"
+	"github.com/casbin/casbin/v3/rbac"
 )
 
 // CachedEnforcer wraps Enforcer and provides decision cache.
@@ -87,6 +89,12 @@ func (e *CachedEnforcer) Enforce(rvals ...interface{}) (bool, error) {
 	return res, err
 }
 
+// EnforceWithRoles decides whether a "subject" can access a "object" with the operation "action",
+// taking into account the roles asserted for this request only. The roles are part of the cache key.
+func (e *CachedEnforcer) EnforceWithRoles(roles RequestRoles, rvals ...interface{}) (bool, error) {
+	return e.Enforce(append([]interface{}{roles}, rvals...)...)
+}
+
 func (e *CachedEnforcer) LoadPolicy() error {
 	if atomic.LoadInt32(&e.enableCache) != 0 {
 		if err := e.cache.Clear(); err != nil {
@@ -173,6 +181,16 @@ func GetCacheKey(params ...interface{}) (string, bool) {
 	return key.String(), true
 }
 
+// SetWatcher sets the current watcher, with the update callback returned by NewWatcherCallback
+// applying the changes to e and invalidating the cache, see Enforcer.SetWatcher.
+func (e *CachedEnforcer) SetWatcher(watcher persist.Watcher) error {
+	e.setWatcher(watcher)
+	if !setsWatcherCallback(watcher) {
+		return nil
+	}
+	return watcher.SetUpdateCallback(invalidatingWatcherCallback(e))
+}
+
 // ClearPolicy clears all policy.
 func (e *CachedEnforcer) ClearPolicy() {
 	if atomic.LoadInt32(&e.enableCache) != 0 {
@@ -183,3 +201,47 @@ func (e *CachedEnforcer) ClearPolicy() {
 	}
 	e.Enforcer.ClearPolicy()
 }
+
+// RegisterLinkConditionFunc registers a link condition function by name for the grouping policy "g", see Enforcer.RegisterLinkConditionFunc.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *CachedEnforcer) RegisterLinkConditionFunc(name string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.Enforcer.RegisterLinkConditionFunc(name, fn))
+}
+
+// RegisterNamedLinkConditionFunc registers a link condition function by name for the grouping policy ptype.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *CachedEnforcer) RegisterNamedLinkConditionFunc(ptype, name string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.Enforcer.RegisterNamedLinkConditionFunc(ptype, name, fn))
+}
+
+// AddNamedLinkConditionFunc adds the condition function fn for the link user->role.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *CachedEnforcer) AddNamedLinkConditionFunc(ptype, user, role string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.Enforcer.AddNamedLinkConditionFunc(ptype, user, role, fn))
+}
+
+// AddNamedDomainLinkConditionFunc adds the condition function fn for the link user->{role, domain}.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *CachedEnforcer) AddNamedDomainLinkConditionFunc(ptype, user, role string, domain string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.Enforcer.AddNamedDomainLinkConditionFunc(ptype, user, role, domain, fn))
+}
+
+// SetNamedLinkConditionFuncParams sets the parameters of the condition function of the link user->role.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *CachedEnforcer) SetNamedLinkConditionFuncParams(ptype, user, role string, params ...string) bool {
+	return e.invalidateCacheIf(e.Enforcer.SetNamedLinkConditionFuncParams(ptype, user, role, params...))
+}
+
+// SetNamedDomainLinkConditionFuncParams sets the parameters of the condition function of the link user->{role, domain}.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *CachedEnforcer) SetNamedDomainLinkConditionFuncParams(ptype, user, role, domain string, params ...string) bool {
+	return e.invalidateCacheIf(e.Enforcer.SetNamedDomainLinkConditionFuncParams(ptype, user, role, domain, params...))
+}
+
+// invalidateCacheIf invalidates the cache if changed is true, and returns changed.
+func (e *CachedEnforcer) invalidateCacheIf(changed bool) bool {
+	if changed {
+		_ = e.InvalidateCache()
+	}
+	return changed
+}
@@ -19,7 +19,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/casbin/casbin/v3/persist"
 	"github.com/casbin/casbin/v3/persist/cache"
+	"github.com/casbin/casbin/v3/rbac"
 )
 
 // SyncedCachedEnforcer wraps Enforcer and provides decision sync cache.
@@ -82,6 +84,12 @@ func (e *SyncedCachedEnforcer) Enforce(rvals ...interface{}) (bool, error) {
 	return res, err
 }
 
+// EnforceWithRoles decides whether a "subject" can access a "object" with the operation "action",
+// taking into account the roles asserted for this request only. The roles are part of the cache key.
+func (e *SyncedCachedEnforcer) EnforceWithRoles(roles RequestRoles, rvals ...interface{}) (bool, error) {
+	return e.Enforce(append([]interface{}{roles}, rvals...)...)
+}
+
 func (e *SyncedCachedEnforcer) LoadPolicy() error {
 	if atomic.LoadInt32(&e.enableCache) != 0 {
 		if err := e.cache.Clear(); err != nil {
@@ -149,6 +157,18 @@ func (e *SyncedCachedEnforcer) InvalidateCache() error {
 	return e.cache.Clear()
 }
 
+// SetWatcher sets the current watcher, with the update callback returned by NewWatcherCallback
+// applying the changes to e under its lock and invalidating the cache, see Enforcer.SetWatcher.
+func (e *SyncedCachedEnforcer) SetWatcher(watcher persist.Watcher) error {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.setWatcher(watcher)
+	if !setsWatcherCallback(watcher) {
+		return nil
+	}
+	return watcher.SetUpdateCallback(invalidatingWatcherCallback(e))
+}
+
 func (e *SyncedCachedEnforcer) checkOneAndRemoveCache(params ...interface{}) (bool, error) {
 	if atomic.LoadInt32(&e.enableCache) != 0 {
 		key, ok := e.getKey(params...)
@@ -178,3 +198,47 @@ func (e *SyncedCachedEnforcer) checkManyAndRemoveCache(rules [][]string) (bool,
 	}
 	return true, nil
 }
+
+// RegisterLinkConditionFunc registers a link condition function by name for the grouping policy "g", see Enforcer.RegisterLinkConditionFunc.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *SyncedCachedEnforcer) RegisterLinkConditionFunc(name string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.SyncedEnforcer.RegisterLinkConditionFunc(name, fn))
+}
+
+// RegisterNamedLinkConditionFunc registers a link condition function by name for the grouping policy ptype.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *SyncedCachedEnforcer) RegisterNamedLinkConditionFunc(ptype, name string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.SyncedEnforcer.RegisterNamedLinkConditionFunc(ptype, name, fn))
+}
+
+// AddNamedLinkConditionFunc adds the condition function fn for the link user->role.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *SyncedCachedEnforcer) AddNamedLinkConditionFunc(ptype, user, role string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.SyncedEnforcer.AddNamedLinkConditionFunc(ptype, user, role, fn))
+}
+
+// AddNamedDomainLinkConditionFunc adds the condition function fn for the link user->{role, domain}.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *SyncedCachedEnforcer) AddNamedDomainLinkConditionFunc(ptype, user, role string, domain string, fn rbac.LinkConditionFunc) bool {
+	return e.invalidateCacheIf(e.SyncedEnforcer.AddNamedDomainLinkConditionFunc(ptype, user, role, domain, fn))
+}
+
+// SetNamedLinkConditionFuncParams sets the parameters of the condition function of the link user->role.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *SyncedCachedEnforcer) SetNamedLinkConditionFuncParams(ptype, user, role string, params ...string) bool {
+	return e.invalidateCacheIf(e.SyncedEnforcer.SetNamedLinkConditionFuncParams(ptype, user, role, params...))
+}
+
+// SetNamedDomainLinkConditionFuncParams sets the parameters of the condition function of the link user->{role, domain}.
+// The cached decisions are invalidated, since the links may have changed.
+func (e *SyncedCachedEnforcer) SetNamedDomainLinkConditionFuncParams(ptype, user, role, domain string, params ...string) bool {
+	return e.invalidateCacheIf(e.SyncedEnforcer.SetNamedDomainLinkConditionFuncParams(ptype, user, role, domain, params...))
+}
+
+// invalidateCacheIf invalidates the cache if changed is true, and returns changed.
+func (e *SyncedCachedEnforcer) invalidateCacheIf(changed bool) bool {
+	if changed {
+		_ = e.InvalidateCache()
+	}
+	return changed
+}
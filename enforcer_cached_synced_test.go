@@ -80,3 +80,23 @@ func TestSyncCache(t *testing.T) {
 	testSyncEnforceCache(t, e, "alice", "data2", "read", true)
 	testSyncEnforceCache(t, e, "alice", "data2", "write", true)
 }
+
+func TestSyncCacheLinkConditionFuncs(t *testing.T) {
+	e, _ := NewSyncedCachedEnforcer("examples/rbac_with_domain_named_conditions_model.conf", "examples/rbac_with_domain_named_conditions_policy.csv")
+	flagFunc := func(args ...string) (bool, error) {
+		return len(args) != 0 && args[0] == "true", nil
+	}
+	enforce := func(res bool) {
+		t.Helper()
+		if myRes, _ := e.Enforce("alice", "domain3", "data3", "read"); myRes != res {
+			t.Errorf("alice, domain3, data3, read: %t, supposed to be %t", myRes, res)
+		}
+	}
+
+	// The cached decisions are invalidated when the link conditions change.
+	enforce(true)
+	e.RegisterNamedLinkConditionFunc("g", "flag", flagFunc)
+	enforce(false)
+	e.SetNamedDomainLinkConditionFuncParams("g", "alice", "data3_admin", "domain3", "true")
+	enforce(true)
+}
@@ -71,3 +71,27 @@ func TestCache(t *testing.T) {
 	testEnforceCache(t, e, "alice", "data2", "read", false)
 	testEnforceCache(t, e, "alice", "data2", "write", false)
 }
+
+func TestCacheLinkConditionFuncs(t *testing.T) {
+	e, _ := NewCachedEnforcer("examples/rbac_with_domain_named_conditions_model.conf", "examples/rbac_with_domain_named_conditions_policy.csv")
+	flagFunc := func(args ...string) (bool, error) {
+		return len(args) != 0 && args[0] == "true", nil
+	}
+	enforce := func(res bool) {
+		t.Helper()
+		if myRes, _ := e.Enforce("alice", "domain3", "data3", "read"); myRes != res {
+			t.Errorf("alice, domain3, data3, read: %t, supposed to be %t", myRes, res)
+		}
+	}
+
+	// The cached decisions are invalidated when the link conditions change.
+	enforce(true)
+	e.RegisterLinkConditionFunc("flag", flagFunc)
+	enforce(false)
+	e.SetNamedDomainLinkConditionFuncParams("g", "alice", "data3_admin", "domain3", "true")
+	enforce(true)
+	e.AddNamedDomainLinkConditionFunc("g", "alice", "data3_admin", "domain3", func(args ...string) (bool, error) {
+		return false, nil
+	})
+	enforce(false)
+}
@@ -73,6 +73,10 @@ func (e *ContextEnforcer) loadPolicyFromAdapterCtx(ctx context.Context, baseMode
 		return nil, err
 	}
 
+	if err := newModel.ValidatePolicySchemas(); err != nil {
+		return nil, err
+	}
+
 	if err := newModel.SortPoliciesBySubjectHierarchy(); err != nil {
 		return nil, err
 	}
@@ -111,6 +115,10 @@ func (e *Enforcer) loadFilteredPolicyCtx(ctx context.Context, filter interface{}
 		return err
 	}
 
+	if err := e.model.ValidatePolicySchemas(); err != nil {
+		return err
+	}
+
 	if err := e.model.SortPoliciesBySubjectHierarchy(); err != nil {
 		return err
 	}
@@ -413,6 +421,11 @@ func (e *ContextEnforcer) SelfUpdatePoliciesCtx(ctx context.Context, sec string,
 
 // addPolicyWithoutNotifyCtx adds a rule to the current policy with context.
 func (e *ContextEnforcer) addPolicyWithoutNotifyCtx(ctx context.Context, sec string, ptype string, rule []string) (bool, error) {
+	rule = e.model.FillPolicyDefaults(sec, ptype, rule)
+	if err := e.validatePolicyRules(sec, ptype, [][]string{rule}); err != nil {
+		return false, err
+	}
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.AddPolicies(sec, ptype, [][]string{rule})
 	}
@@ -435,11 +448,19 @@ func (e *ContextEnforcer) addPolicyWithoutNotifyCtx(ctx context.Context, sec str
 		return false, err
 	}
 
+	if sec == "p" {
+		e.compileEvalRules(ptype, [][]string{rule})
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, [][]string{rule})
 		if err != nil {
 			return true, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, [][]string{rule})
+		if err != nil {
+			return true, err
+		}
 	}
 
 	return true, nil
@@ -447,6 +468,11 @@ func (e *ContextEnforcer) addPolicyWithoutNotifyCtx(ctx context.Context, sec str
 
 // addPoliciesWithoutNotifyCtx adds rules to the current policy with context.
 func (e *ContextEnforcer) addPoliciesWithoutNotifyCtx(ctx context.Context, sec string, ptype string, rules [][]string, autoRemoveRepeat bool) (bool, error) {
+	rules = e.model.FillPoliciesDefaults(sec, ptype, rules)
+	if err := e.validatePolicyRules(sec, ptype, rules); err != nil {
+		return false, err
+	}
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.AddPolicies(sec, ptype, rules)
 	}
@@ -471,6 +497,10 @@ func (e *ContextEnforcer) addPoliciesWithoutNotifyCtx(ctx context.Context, sec s
 		return false, err
 	}
 
+	if sec == "p" {
+		e.compileEvalRules(ptype, rules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, rules)
 		if err != nil {
@@ -488,6 +518,8 @@ func (e *ContextEnforcer) addPoliciesWithoutNotifyCtx(ctx context.Context, sec s
 
 // removePolicyWithoutNotifyCtx removes a rule from the current policy with context.
 func (e *ContextEnforcer) removePolicyWithoutNotifyCtx(ctx context.Context, sec string, ptype string, rule []string) (bool, error) {
+	rule = e.model.FillPolicyDefaults(sec, ptype, rule)
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.RemovePolicies(sec, ptype, [][]string{rule})
 	}
@@ -505,11 +537,19 @@ func (e *ContextEnforcer) removePolicyWithoutNotifyCtx(ctx context.Context, sec
 		return ruleRemoved, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, [][]string{rule})
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, [][]string{rule})
 		if err != nil {
 			return ruleRemoved, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, [][]string{rule})
+		if err != nil {
+			return ruleRemoved, err
+		}
 	}
 
 	return ruleRemoved, nil
@@ -517,6 +557,8 @@ func (e *ContextEnforcer) removePolicyWithoutNotifyCtx(ctx context.Context, sec
 
 // removePoliciesWithoutNotifyCtx removes rules from the current policy with context.
 func (e *ContextEnforcer) removePoliciesWithoutNotifyCtx(ctx context.Context, sec string, ptype string, rules [][]string) (bool, error) {
+	rules = e.model.FillPoliciesDefaults(sec, ptype, rules)
+
 	if hasPolicies, err := e.model.HasPolicies(sec, ptype, rules); !hasPolicies || err != nil {
 		return hasPolicies, err
 	}
@@ -538,11 +580,19 @@ func (e *ContextEnforcer) removePoliciesWithoutNotifyCtx(ctx context.Context, se
 		return rulesRemoved, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, rules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, rules)
 		if err != nil {
 			return rulesRemoved, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, rules)
+		if err != nil {
+			return rulesRemoved, err
+		}
 	}
 	return rulesRemoved, nil
 }
@@ -570,11 +620,19 @@ func (e *ContextEnforcer) removeFilteredPolicyWithoutNotifyCtx(ctx context.Conte
 		return ruleRemoved, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, effects)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, effects)
 		if err != nil {
 			return ruleRemoved, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, effects)
+		if err != nil {
+			return ruleRemoved, err
+		}
 	}
 
 	return ruleRemoved, nil
@@ -582,6 +640,12 @@ func (e *ContextEnforcer) removeFilteredPolicyWithoutNotifyCtx(ctx context.Conte
 
 // updatePolicyWithoutNotifyCtx updates a policy rule in the current policy with context.
 func (e *ContextEnforcer) updatePolicyWithoutNotifyCtx(ctx context.Context, sec string, ptype string, oldRule, newRule []string) (bool, error) {
+	oldRule = e.model.FillPolicyDefaults(sec, ptype, oldRule)
+	newRule = e.model.FillPolicyDefaults(sec, ptype, newRule)
+	if err := e.validatePolicyRules(sec, ptype, [][]string{newRule}); err != nil {
+		return false, err
+	}
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.UpdatePolicy(sec, ptype, oldRule, newRule)
 	}
@@ -598,21 +662,40 @@ func (e *ContextEnforcer) updatePolicyWithoutNotifyCtx(ctx context.Context, sec
 		return ruleUpdated, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, [][]string{oldRule})
+		e.compileEvalRules(ptype, [][]string{newRule})
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, [][]string{oldRule}) // remove the old rule
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, [][]string{oldRule})
+		if err != nil {
+			return ruleUpdated, err
+		}
 		err = e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, [][]string{newRule}) // add the new rule
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, [][]string{newRule})
+		if err != nil {
+			return ruleUpdated, err
+		}
 	}
 
 	return ruleUpdated, nil
 }
 
 func (e *ContextEnforcer) updatePoliciesWithoutNotifyCtx(ctx context.Context, sec string, ptype string, oldRules [][]string, newRules [][]string) (bool, error) {
+	oldRules = e.model.FillPoliciesDefaults(sec, ptype, oldRules)
+	newRules = e.model.FillPoliciesDefaults(sec, ptype, newRules)
+	if err := e.validatePolicyRules(sec, ptype, newRules); err != nil {
+		return false, err
+	}
+
 	if len(newRules) != len(oldRules) {
 		return false, fmt.Errorf("the length of oldRules should be equal to the length of newRules, but got the length of oldRules is %d, the length of newRules is %d", len(oldRules), len(newRules))
 	}
@@ -634,15 +717,28 @@ func (e *ContextEnforcer) updatePoliciesWithoutNotifyCtx(ctx context.Context, se
 		return ruleUpdated, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, oldRules)
+		e.compileEvalRules(ptype, newRules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, oldRules) // remove the old rules
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, oldRules)
+		if err != nil {
+			return ruleUpdated, err
+		}
 		err = e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, newRules) // add the new rules
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, newRules)
+		if err != nil {
+			return ruleUpdated, err
+		}
 	}
 
 	return ruleUpdated, nil
@@ -803,6 +899,11 @@ func (e *ContextEnforcer) updateFilteredPoliciesCtx(ctx context.Context, sec str
 }
 
 func (e *ContextEnforcer) updateFilteredPoliciesWithoutNotifyCtx(ctx context.Context, sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	newRules = e.model.FillPoliciesDefaults(sec, ptype, newRules)
+	if err := e.validatePolicyRules(sec, ptype, newRules); err != nil {
+		return nil, err
+	}
+
 	var (
 		oldRules [][]string
 		err      error
@@ -843,15 +944,28 @@ func (e *ContextEnforcer) updateFilteredPoliciesWithoutNotifyCtx(ctx context.Con
 		return make([][]string, 0), nil
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, oldRules)
+		e.compileEvalRules(ptype, newRules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, oldRules) // remove the old rules
 		if err != nil {
 			return oldRules, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, oldRules)
+		if err != nil {
+			return oldRules, err
+		}
 		err = e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, newRules) // add the new rules
 		if err != nil {
 			return oldRules, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, newRules)
+		if err != nil {
+			return oldRules, err
+		}
 	}
 
 	return oldRules, nil
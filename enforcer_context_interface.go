@@ -14,7 +14,24 @@
 
 package casbin
 
-import "context"
+import (
+	"context"
+
+	"github.com/casbin/casbin/v3/model"
+)
+
+var _ IEnforcerWithContext = &Enforcer{}
+var _ IEnforcerWithContext = &SyncedEnforcer{}
+
+// IEnforcerWithContext is the interface of the enforcers deciding requests under a context, see Enforcer.EnforceWithContext.
+// It is separate from IEnforcerContext, so that the implementations of IEnforcerContext are not required to implement it.
+type IEnforcerWithContext interface {
+	IEnforcer
+	EnforceWithContext(ctx context.Context, rvals ...interface{}) (bool, error)
+	EnforceExWithContext(ctx context.Context, rvals ...interface{}) (bool, []string, error)
+	BatchEnforceWithContext(ctx context.Context, requests [][]interface{}) ([]bool, error)
+	AddContextFunction(name string, function model.ContextFunction)
+}
 
 type IEnforcerContext interface {
 	IEnforcer
@@ -16,8 +16,14 @@ package casbin
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/casbin/casbin/v3/log"
+	"github.com/casbin/casbin/v3/model"
+	fileadapter "github.com/casbin/casbin/v3/persist/file-adapter"
+	"github.com/casbin/casbin/v3/util"
 )
 
 func TestIEnforcerContext_BasicOperations(t *testing.T) {
@@ -230,3 +236,142 @@ func TestIEnforcerContext_SelfMethods(t *testing.T) {
 		t.Error("SelfRemovePolicyCtx should return true for existing policy")
 	}
 }
+
+type enforceCtxKey struct{}
+
+func TestEnforceWithContext(t *testing.T) {
+	e, _ := NewEnforcer("examples/keymatch_custom_model.conf", "examples/keymatch2_policy.csv")
+	e.AddContextFunction("keyMatchCustom", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		if tenant, ok := ctx.Value(enforceCtxKey{}).(string); ok && tenant == "blocked" {
+			return false, nil
+		}
+		return util.KeyMatch2(args[0].(string), args[1].(string)), nil
+	})
+
+	ok, err := e.EnforceWithContext(context.Background(), "alice", "/alice_data/resource1", "GET")
+	if err != nil || !ok {
+		t.Errorf("EnforceWithContext() = %v, %v, supposed to be true", ok, err)
+	}
+	// Functions see the values of the context.
+	ctx := context.WithValue(context.Background(), enforceCtxKey{}, "blocked")
+	ok, err = e.EnforceWithContext(ctx, "alice", "/alice_data/resource1", "GET")
+	if err != nil || ok {
+		t.Errorf("EnforceWithContext() = %v, %v, supposed to be false", ok, err)
+	}
+	// The other enforce methods use a background context.
+	testEnforce(t, e, "alice", "/alice_data/resource1", "GET", true)
+	// The matcher is cached and shared by all the contexts, the derived ones included.
+	if _, ok := e.matcherMap.Load(passEvalParameters(e.model["m"]["m"].Value, e.fm.GetContextFunctions())); !ok {
+		t.Error("the matcher calling a context function should be cached")
+	}
+	ok, err = e.EnforceWithContext(context.TODO(), "alice", "/alice_data/resource1", "GET")
+	if err != nil || !ok {
+		t.Errorf("EnforceWithContext() = %v, %v, supposed to be true", ok, err)
+	}
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ok, err = e.EnforceWithContext(derived, "alice", "/alice_data/resource1", "GET")
+	if err != nil || ok {
+		t.Errorf("EnforceWithContext() = %v, %v, supposed to be false", ok, err)
+	}
+
+	ok, explain, err := e.EnforceExWithContext(context.Background(), "alice", "/alice_data/resource1", "GET")
+	if err != nil || !ok || !util.ArrayEquals(explain, []string{"alice", "/alice_data/:resource", "GET"}) {
+		t.Errorf("EnforceExWithContext() = %v, %v, %v", ok, explain, err)
+	}
+
+	results, err := e.BatchEnforceWithContext(ctx, [][]interface{}{{"alice", "/alice_data/resource1", "GET"}, {"alice", "/alice_data2/myid", "GET"}})
+	if err != nil || len(results) != 2 || results[0] || results[1] {
+		t.Errorf("BatchEnforceWithContext() = %v, %v", results, err)
+	}
+}
+
+func TestEnforceWithCanceledContext(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	var entries []*log.LogEntry
+	logger := log.NewDefaultLogger()
+	_ = logger.SetLogCallback(func(entry *log.LogEntry) error {
+		entryCopy := *entry
+		entries = append(entries, &entryCopy)
+		return nil
+	})
+	e.SetLogger(logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ok, err := e.EnforceWithContext(ctx, "alice", "data1", "read")
+	if ok || !errors.Is(err, context.Canceled) {
+		t.Errorf("EnforceWithContext() = %v, %v, supposed to be canceled", ok, err)
+	}
+	if len(entries) != 1 || !entries[0].Canceled || !errors.Is(entries[0].Error, context.Canceled) {
+		t.Errorf("the cancellation should be recorded in the log entry")
+	}
+
+	// The scan stops as soon as the context is done.
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = visit(p.sub) && r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+	e, _ = NewEnforcer(m, fileadapter.NewAdapter("examples/basic_policy.csv"))
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	visits := 0
+	e.AddContextFunction("visit", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		visits++
+		cancel()
+		return true, nil
+	})
+	_, err = e.EnforceWithContext(ctx, "bob", "data2", "write")
+	if !errors.Is(err, context.Canceled) || visits != 1 {
+		t.Errorf("EnforceWithContext() error = %v after %d rules, supposed to be canceled after 1", err, visits)
+	}
+
+	ok, err = e.EnforceWithContext(context.Background(), "bob", "data2", "write")
+	if err != nil || !ok {
+		t.Errorf("EnforceWithContext() = %v, %v, supposed to be true", ok, err)
+	}
+}
+
+func TestContextFunctionInEval(t *testing.T) {
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj
+
+[policy_definition]
+p = sub_rule, obj
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = eval(p.sub_rule) && r.obj == p.obj
+`)
+	e, _ := NewEnforcer(m)
+	e.AddContextFunction("tenant", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		tenant, _ := ctx.Value(enforceCtxKey{}).(string)
+		return tenant, nil
+	})
+	_, _ = e.AddPolicy("tenant() == r.sub", "data1")
+
+	// The context functions called by the rules get the context of the request, without argument as well.
+	for _, tenant := range []string{"alice", "bob"} {
+		ctx := context.WithValue(context.Background(), enforceCtxKey{}, tenant)
+		ok, err := e.EnforceWithContext(ctx, "alice", "data1")
+		if err != nil || ok != (tenant == "alice") {
+			t.Errorf("EnforceWithContext() of tenant %s = %v, %v", tenant, ok, err)
+		}
+	}
+	if ok, err := e.Enforce("alice", "data1"); err != nil || ok {
+		t.Errorf("Enforce() = %v, %v, supposed to be false without tenant", ok, err)
+	}
+}
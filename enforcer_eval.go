@@ -0,0 +1,209 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/util"
+	"github.com/casbin/govaluate"
+)
+
+// evalParametersName is the parameter passed by the matcher to eval() and to the context functions,
+// so that the compiled matcher does not depend on the parameters or the context of a request and can be cached.
+const evalParametersName = "casbinEvalParameters"
+
+// functionCallRegex matches the calls of functions, with the closing parenthesis of a call without argument.
+var functionCallRegex = regexp.MustCompile(`(^|[^.\w])(\w+)\(\s*(\))?`)
+
+// passEvalParameters passes the parameters of the request to the calls of eval() and of contextFunctions in exp.
+func passEvalParameters(exp string, contextFunctions map[string]model.ContextFunction) string {
+	return functionCallRegex.ReplaceAllStringFunc(exp, func(call string) string {
+		groups := functionCallRegex.FindStringSubmatch(call)
+		if _, ok := contextFunctions[groups[2]]; !ok && groups[2] != "eval" {
+			return call
+		}
+		if groups[3] != "" {
+			return groups[1] + groups[2] + "(" + evalParametersName + ")"
+		}
+		return groups[1] + groups[2] + "(" + evalParametersName + ", "
+	})
+}
+
+// generateContextFunction returns the function calling function with the context of the request,
+// which is taken from the parameters of the request passed by the matcher as first argument.
+func generateContextFunction(name string, function model.ContextFunction) govaluate.ExpressionFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) != 0 {
+			if parameters, ok := args[0].(enforceParameters); ok {
+				return function(parameters.ctx, args[1:]...)
+			}
+		}
+		return nil, fmt.Errorf("function %s must be called by a matcher", name)
+	}
+}
+
+// generateEvalFunction returns the eval() function evaluating a rule of ptype with the parameters of the request,
+// the compiled rules are cached in expressions by content unless it is nil.
+// The rules violating policy fail unless it is nil.
+func generateEvalFunction(functions map[string]govaluate.ExpressionFunction, contextFunctions map[string]model.ContextFunction,
+	expressions *sync.Map, ptype string, policy *EvalPolicy) govaluate.ExpressionFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("function eval(subrule string) expected %d arguments, but got %d", 1, len(args)-1)
+		}
+
+		parameters, ok := args[0].(enforceParameters)
+		if !ok {
+			return nil, errors.New("function eval(subrule string) must be called by a matcher")
+		}
+		rule, ok := args[1].(string)
+		if !ok {
+			return nil, errors.New("argument of eval(subrule string) must be a string")
+		}
+
+		expr, err := compileEvalExpression(rule, functions, contextFunctions, expressions, ptype, policy)
+		if err != nil {
+			return nil, err
+		}
+		return expr.Eval(parameters)
+	}
+}
+
+// evalExpressionKey returns the key of a compiled eval() rule of ptype, the rules are validated by the policy of ptype.
+func evalExpressionKey(ptype string, rule string) string {
+	return ptype + "$$" + rule
+}
+
+// compileEvalExpression validates and compiles the eval() rule of ptype, or gets it from expressions when it is not nil.
+func compileEvalExpression(rule string, functions map[string]govaluate.ExpressionFunction, contextFunctions map[string]model.ContextFunction,
+	expressions *sync.Map, ptype string, policy *EvalPolicy) (*govaluate.EvaluableExpression, error) {
+	key := evalExpressionKey(ptype, rule)
+	if expressions != nil {
+		if cached, ok := expressions.Load(key); ok {
+			return cached.(*govaluate.EvaluableExpression), nil
+		}
+	}
+
+	if policy != nil {
+		if err := policy.validate(ptype, rule); err != nil {
+			return nil, err
+		}
+	}
+	expression := passEvalParameters(util.EscapeAssertion(rule), contextFunctions)
+	expr, err := govaluate.NewEvaluableExpressionWithFunctions(expression, functions)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing eval parameter: %s, %s", expression, err.Error())
+	}
+	if expressions != nil {
+		if previous, loaded := expressions.LoadOrStore(key, expr); loaded {
+			return previous.(*govaluate.EvaluableExpression), nil
+		}
+	}
+	return expr, nil
+}
+
+// generateGFunction returns the g() function of a role definition, or nil if it has no role manager.
+func (e *Enforcer) generateGFunction(ast *model.Assertion) govaluate.ExpressionFunction {
+	// g must be a normal role definition (ast.RM != nil)
+	//   or a conditional role definition (ast.CondRM != nil)
+	if ast.CondRM != nil {
+		return util.GenerateConditionalGFunction(ast.CondRM)
+	}
+	if ast.RM != nil {
+		return util.GenerateGFunction(ast.RM, e.gFunctionCache)
+	}
+	return nil
+}
+
+// getEvalFieldIndexes returns the indexes of the fields of ptype evaluated by eval() in the matchers.
+func (e *Enforcer) getEvalFieldIndexes(ptype string) []int {
+	assertion, ok := e.model["p"][ptype]
+	if !ok {
+		return nil
+	}
+
+	var indexes []int
+	for _, matcher := range e.model["m"] {
+		for _, value := range util.GetEvalValue(matcher.Value) {
+			value = strings.TrimSpace(value)
+			if !strings.HasPrefix(value, ptype+"_") {
+				continue
+			}
+			for i, token := range assertion.Tokens {
+				if token == value {
+					indexes = append(indexes, i)
+				}
+			}
+		}
+	}
+	return indexes
+}
+
+// compileEvalRules compiles the eval() rules of the policy rules of ptype, so that they are not compiled
+// by the first request evaluating them. The rules failing to compile or to validate are reported when they are evaluated.
+func (e *Enforcer) compileEvalRules(ptype string, rules [][]string) {
+	indexes := e.getEvalFieldIndexes(ptype)
+	if len(indexes) == 0 || len(rules) == 0 {
+		return
+	}
+
+	functions := e.fm.GetFunctions()
+	for key, ast := range e.model["g"] {
+		if function := e.generateGFunction(ast); function != nil {
+			functions[key] = function
+		}
+	}
+	contextFunctions := e.fm.GetContextFunctions()
+	for name, function := range contextFunctions {
+		functions[name] = generateContextFunction(name, function)
+	}
+	expressions := e.evalExpressions
+	policy := e.evalPolicies[ptype]
+	functions["eval"] = generateEvalFunction(functions, contextFunctions, expressions, ptype, policy)
+
+	for _, rule := range rules {
+		for _, i := range indexes {
+			if i < len(rule) {
+				_, _ = compileEvalExpression(rule[i], functions, contextFunctions, expressions, ptype, policy)
+			}
+		}
+	}
+}
+
+// forgetEvalRules removes the compiled eval() rules of the policy rules of ptype from the cache.
+func (e *Enforcer) forgetEvalRules(ptype string, rules [][]string) {
+	indexes := e.getEvalFieldIndexes(ptype)
+	for _, rule := range rules {
+		for _, i := range indexes {
+			if i >= len(rule) {
+				continue
+			}
+			e.evalExpressions.Delete(evalExpressionKey(ptype, rule[i]))
+		}
+	}
+}
+
+// compileAllEvalRules compiles the eval() rules of the whole policy.
+func (e *Enforcer) compileAllEvalRules() {
+	for ptype, assertion := range e.model["p"] {
+		e.compileEvalRules(ptype, assertion.Policy)
+	}
+}
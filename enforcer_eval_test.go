@@ -0,0 +1,106 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"testing"
+)
+
+func testEvalExpressionCached(t *testing.T, e *Enforcer, rule string, res bool) {
+	t.Helper()
+	if _, ok := e.evalExpressions.Load(evalExpressionKey("p", rule)); ok != res {
+		t.Errorf("eval rule %q cached: %t, supposed to be %t", rule, ok, res)
+	}
+}
+
+func TestEvalExpressionCache(t *testing.T) {
+	e, _ := NewEnforcer("examples/abac_rule_model.conf", "examples/abac_rule_policy.csv")
+
+	// The rules are compiled when the policy is loaded.
+	testEvalExpressionCached(t, e, "r.sub.Age > 18", true)
+	testEvalExpressionCached(t, e, "r.sub.Age < 60", true)
+
+	sub := newTestSubject("alice", 70)
+	testEnforce(t, e, sub, "/data1", "read", true)
+	testEnforce(t, e, sub, "/data2", "write", false)
+	if _, ok := e.matcherMap.Load(passEvalParameters(e.model["m"]["m"].Value, nil)); !ok {
+		t.Error("a matcher calling eval() should be cached")
+	}
+
+	// The rules are compiled when they are added and forgotten when they are removed or updated.
+	_, _ = e.AddPolicy("r.sub.Age > 65", "/data3", "read")
+	testEvalExpressionCached(t, e, "r.sub.Age > 65", true)
+	testEnforce(t, e, sub, "/data3", "read", true)
+
+	_, _ = e.UpdatePolicy([]string{"r.sub.Age > 65", "/data3", "read"}, []string{"r.sub.Age > 75", "/data3", "read"})
+	testEvalExpressionCached(t, e, "r.sub.Age > 65", false)
+	testEvalExpressionCached(t, e, "r.sub.Age > 75", true)
+	testEnforce(t, e, sub, "/data3", "read", false)
+
+	_, _ = e.RemovePolicy("r.sub.Age > 75", "/data3", "read")
+	testEvalExpressionCached(t, e, "r.sub.Age > 75", false)
+	testEnforce(t, e, sub, "/data3", "read", false)
+
+	// A malformed rule is reported when it is evaluated.
+	_, _ = e.AddPolicy("r.sub.Age >", "/data4", "read")
+	if _, err := e.Enforce(sub, "/data4", "read"); err == nil {
+		t.Error("a malformed eval() rule should fail")
+	}
+}
+
+func TestEvalExpressionCacheAddFunction(t *testing.T) {
+	e, _ := NewEnforcer("examples/abac_rule_model.conf", "examples/abac_rule_policy.csv")
+	sub := newTestSubject("alice", 70)
+	testEnforce(t, e, sub, "/data1", "read", true)
+
+	// A function added after the matcher is compiled is available to the eval() rules.
+	_, _ = e.AddPolicy("isVip(r.sub.Name)", "/data3", "read")
+	e.AddFunction("isVip", func(args ...interface{}) (interface{}, error) {
+		return args[0] == "alice", nil
+	})
+	ok, err := e.Enforce(sub, "/data3", "read")
+	if err != nil || !ok {
+		t.Errorf("Enforce() = %t, %v, supposed to be true", ok, err)
+	}
+}
+
+func TestEvalExpressionCacheWithRequestRoles(t *testing.T) {
+	e, _ := NewEnforcer("examples/abac_rule_model.conf", "examples/abac_rule_policy.csv")
+	e.invalidateMatcherMap()
+
+	ok, err := e.EnforceWithRoles(NewRequestRoles("alice", "admin"), newTestSubject("alice", 30), "/data2", "write")
+	if err != nil || !ok {
+		t.Errorf("EnforceWithRoles: %t, %v", ok, err)
+	}
+	// The functions bound to the rules depend on the request, so they are not cached.
+	testEvalExpressionCached(t, e, "r.sub.Age < 60", false)
+}
+
+func TestEvalExpressionCacheCtx(t *testing.T) {
+	ce, _ := NewContextEnforcer("examples/abac_rule_model.conf", "examples/abac_rule_policy.csv")
+	e := ce.(*ContextEnforcer)
+	ctx := context.Background()
+
+	_, _ = e.AddPolicyCtx(ctx, "r.sub.Age > 65", "/data3", "read")
+	testEvalExpressionCached(t, e.Enforcer, "r.sub.Age > 65", true)
+
+	_, _ = e.UpdatePolicyCtx(ctx, []string{"r.sub.Age > 65", "/data3", "read"}, []string{"r.sub.Age > 75", "/data3", "read"})
+	testEvalExpressionCached(t, e.Enforcer, "r.sub.Age > 65", false)
+	testEvalExpressionCached(t, e.Enforcer, "r.sub.Age > 75", true)
+
+	_, _ = e.RemovePolicyCtx(ctx, "r.sub.Age > 75", "/data3", "read")
+	testEvalExpressionCached(t, e.Enforcer, "r.sub.Age > 75", false)
+}
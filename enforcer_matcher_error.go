@@ -0,0 +1,60 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"sync/atomic"
+
+	"github.com/casbin/casbin/v3/log"
+)
+
+// MatcherErrorPolicy decides how a failure to evaluate the matcher on a policy rule is handled,
+// e.g. a malformed eval() rule or a matcher result that is not a boolean.
+type MatcherErrorPolicy int
+
+const (
+	// MatcherErrorAbort aborts the enforcement and returns the error, it is the default.
+	MatcherErrorAbort MatcherErrorPolicy = iota
+	// MatcherErrorSkip treats the faulty rule as not matching.
+	MatcherErrorSkip
+	// MatcherErrorDeny treats the faulty rule as a matching deny rule, merged with the other rules by the effect.
+	MatcherErrorDeny
+)
+
+// SetMatcherErrorPolicy sets how a failure to evaluate the matcher on a policy rule is handled.
+// Unless the enforcement is aborted, the faulty rules are counted and reported to the logger in the
+// FaultyRules of the enforce log entry.
+func (e *Enforcer) SetMatcherErrorPolicy(policy MatcherErrorPolicy) {
+	e.matcherErrorPolicy = policy
+}
+
+// GetFaultyRuleCount returns the number of times the evaluation of a policy rule failed and the rule was skipped
+// or denied per the matcher error policy.
+func (e *Enforcer) GetFaultyRuleCount() uint64 {
+	return atomic.LoadUint64(&e.faultyRuleCount)
+}
+
+// ResetFaultyRuleCount resets the number of faulty rules to zero.
+func (e *Enforcer) ResetFaultyRuleCount() {
+	atomic.StoreUint64(&e.faultyRuleCount, 0)
+}
+
+// reportFaultyRule counts a rule whose evaluation failed and reports it to the log entry of the enforcement.
+func (e *Enforcer) reportFaultyRule(logEntry *log.LogEntry, rule []string) {
+	atomic.AddUint64(&e.faultyRuleCount, 1)
+	if e.logger != nil && logEntry != nil {
+		logEntry.FaultyRules = append(logEntry.FaultyRules, append([]string(nil), rule...))
+	}
+}
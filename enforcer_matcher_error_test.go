@@ -0,0 +1,114 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/log"
+	"github.com/casbin/casbin/v3/model"
+)
+
+func newFaultyRuleEnforcer(t *testing.T) *Enforcer {
+	t.Helper()
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub_rule, obj, act, eft
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = r.obj == p.obj && r.act == p.act && eval(p.sub_rule)
+`)
+	e, err := NewEnforcer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = e.AddPolicies([][]string{
+		{"r.sub.Age > 18", "/data1", "read", "allow"},
+		{"r.sub.Age >", "/data1", "read", "allow"},
+		{"r.sub.Age > 18", "/data2", "read", "allow"},
+	})
+	return e
+}
+
+func TestMatcherErrorPolicy(t *testing.T) {
+	e := newFaultyRuleEnforcer(t)
+	sub := struct{ Age int }{Age: 30}
+
+	// The faulty rule aborts the enforcement by default.
+	if _, err := e.Enforce(sub, "/data1", "read"); err == nil {
+		t.Error("a faulty rule should abort the enforcement")
+	}
+	if e.GetFaultyRuleCount() != 0 {
+		t.Error("an aborted enforcement should not count the faulty rule")
+	}
+
+	e.SetMatcherErrorPolicy(MatcherErrorSkip)
+	testEnforce(t, e, sub, "/data1", "read", true)
+	testEnforce(t, e, sub, "/data2", "read", true)
+	if e.GetFaultyRuleCount() != 1 {
+		t.Errorf("faulty rule count = %d, supposed to be 1", e.GetFaultyRuleCount())
+	}
+
+	e.SetMatcherErrorPolicy(MatcherErrorDeny)
+	testEnforce(t, e, sub, "/data1", "read", false)
+	testEnforce(t, e, sub, "/data2", "read", true)
+	_, explain, _ := e.EnforceEx(sub, "/data1", "read")
+	if strings.Join(explain, ", ") != "r.sub.Age >, /data1, read, allow" {
+		t.Errorf("the faulty rule should decide, got %v", explain)
+	}
+	if e.GetFaultyRuleCount() != 3 {
+		t.Errorf("faulty rule count = %d, supposed to be 3", e.GetFaultyRuleCount())
+	}
+
+	e.ResetFaultyRuleCount()
+	if e.GetFaultyRuleCount() != 0 {
+		t.Error("the faulty rule count should be reset")
+	}
+}
+
+func TestMatcherErrorPolicyLogger(t *testing.T) {
+	e := newFaultyRuleEnforcer(t)
+	e.SetMatcherErrorPolicy(MatcherErrorSkip)
+
+	var buf bytes.Buffer
+	var entries []*log.LogEntry
+	logger := log.NewDefaultLogger()
+	logger.SetOutput(&buf)
+	_ = logger.SetLogCallback(func(entry *log.LogEntry) error {
+		entryCopy := *entry
+		entries = append(entries, &entryCopy)
+		return nil
+	})
+	e.SetLogger(logger)
+
+	testEnforce(t, e, struct{ Age int }{Age: 30}, "/data1", "read", true)
+	if len(entries) != 1 || len(entries[0].FaultyRules) != 1 || entries[0].FaultyRules[0][0] != "r.sub.Age >" {
+		t.Fatalf("the faulty rule should be reported to the logger")
+	}
+	if entries[0].Error != nil {
+		t.Errorf("a skipped rule is not an error of the enforcement: %v", entries[0].Error)
+	}
+	if !strings.Contains(buf.String(), "FaultyRules=[[r.sub.Age > /data1 read allow]]") {
+		t.Errorf("the faulty rule should be logged, got %q", buf.String())
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/casbin/casbin/v3/constant"
+	"github.com/casbin/govaluate"
+)
+
+// reasonTemplateRegex matches the placeholders of a message, e.g. "{r.sub}" or "{p.obj}".
+var reasonTemplateRegex = regexp.MustCompile(`\{\s*([rp])\.(\w+)\s*\}`)
+
+// enforceReason receives the reason of an enforcement.
+type enforceReason struct {
+	reason string
+}
+
+// EnforceWithReason decides whether a "subject" can access a "object" with the operation "action"
+// and returns a human-readable reason for the decision.
+// The reason of a decision made by a rule is the value of its "message" field, declared in the model e.g.
+//
+// [policy_definition]
+// p = sub, obj, act, eft, message
+//
+// p, alice, data2, write, deny, {r.sub} cannot modify {r.obj} during the audit.
+//
+// The placeholders {r.<token>} and {p.<token>} are replaced with the values of the request and of the rule.
+// A request denied by a rule without message gets a reason naming the rule, and a request denied because
+// no rule applies gets a reason synthesized from what was missing: a rule for the subject, for the object
+// or for the action. An allowed request without message gets an empty reason.
+func (e *Enforcer) EnforceWithReason(rvals ...interface{}) (bool, string, error) {
+	reason := &enforceReason{}
+	result, err := e.enforce(context.Background(), "", nil, append([]interface{}{reason}, rvals...)...)
+	return result, reason.reason, err
+}
+
+// getEnforceReason returns the reason of the decision on a request of rtype,
+// explainIndex is the index of the deciding rule of ptype or -1.
+func (e *Enforcer) getEnforceReason(expression *govaluate.EvaluableExpression, parameters enforceParameters,
+	rtype string, ptype string, result bool, explainIndex int) string {
+	policy := e.model["p"][ptype].Policy
+	if explainIndex != -1 && explainIndex < len(policy) {
+		// the rule may have been loaded without its optional fields
+		rule := e.model.FillPolicyDefaults("p", ptype, policy[explainIndex])
+		parameters.pVals = rule
+		if i, ok := parameters.pTokens[ptype+"_"+constant.MessageIndex]; ok && i < len(rule) && strings.TrimSpace(rule[i]) != "" {
+			return formatReason(rule[i], parameters, rtype, ptype)
+		}
+		if result {
+			return ""
+		}
+		return fmt.Sprintf("denied by rule: %s", strings.Join(rule, ", "))
+	}
+	if result {
+		return ""
+	}
+
+	sub := parameters.requestValue(rtype, constant.SubjectIndex)
+	obj := parameters.requestValue(rtype, constant.ObjectIndex)
+	act := parameters.requestValue(rtype, constant.ActionIndex)
+	switch {
+	case sub == nil:
+		return "no rule applies to the request"
+	case !e.matchesAnyRule(expression, parameters, rtype, ptype, constant.ObjectIndex, constant.ActionIndex):
+		return fmt.Sprintf("no role or rule applies to subject %v", sub)
+	case obj != nil && !e.matchesAnyRule(expression, parameters, rtype, ptype, constant.ActionIndex):
+		return fmt.Sprintf("no rule of subject %v matches object %v", sub, obj)
+	case act != nil:
+		return fmt.Sprintf("no rule of subject %v allows action %v", sub, act)
+	default:
+		return fmt.Sprintf("no rule of subject %v applies to the request", sub)
+	}
+}
+
+// matchesAnyRule reports whether the matcher matches a rule of ptype once the values of fields of the request of rtype
+// are replaced with the values of the rule, i.e. whether a rule applies to the request regardless of fields.
+func (e *Enforcer) matchesAnyRule(expression *govaluate.EvaluableExpression, parameters enforceParameters, rtype string, ptype string, fields ...string) bool {
+	request := parameters.rVals
+	rvals := make([]interface{}, len(request))
+	parameters.rVals = rvals
+	for _, pvals := range e.model["p"][ptype].Policy {
+		pvals = e.model.FillPolicyDefaults("p", ptype, pvals)
+		if len(pvals) != len(parameters.pTokens) {
+			continue
+		}
+		copy(rvals, request)
+		for _, field := range fields {
+			ri, ok1 := parameters.rTokens[rtype+"_"+field]
+			pi, ok2 := parameters.pTokens[ptype+"_"+field]
+			if ok1 && ok2 {
+				rvals[ri] = pvals[pi]
+			}
+		}
+		parameters.pVals = pvals
+		result, err := expression.Eval(parameters)
+		if err != nil {
+			continue
+		}
+		switch result := result.(type) {
+		case bool:
+			if result {
+				return true
+			}
+		case float64:
+			if result != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestValue returns the request value of field, or nil if the request definition rtype has no such token.
+func (p enforceParameters) requestValue(rtype string, field string) interface{} {
+	if i, ok := p.rTokens[rtype+"_"+field]; ok {
+		return p.rVals[i]
+	}
+	return nil
+}
+
+// formatReason replaces the placeholders of message with the values of the request of rtype and of the rule of ptype,
+// e.g. {r.sub} is the value of the token r2_sub of a request of r2.
+func formatReason(message string, parameters enforceParameters, rtype string, ptype string) string {
+	return reasonTemplateRegex.ReplaceAllStringFunc(message, func(placeholder string) string {
+		groups := reasonTemplateRegex.FindStringSubmatch(placeholder)
+		token := rtype + "_" + groups[2]
+		if groups[1] == "p" {
+			token = ptype + "_" + groups[2]
+			if i, ok := parameters.pTokens[token]; !ok || i >= len(parameters.pVals) {
+				return placeholder
+			}
+		}
+		value, err := parameters.Get(token)
+		if err != nil {
+			return placeholder
+		}
+		return fmt.Sprint(value)
+	})
+}
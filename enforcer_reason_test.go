@@ -0,0 +1,163 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	stringadapter "github.com/casbin/casbin/v3/persist/string-adapter"
+)
+
+func testEnforceWithReason(t *testing.T, e *Enforcer, sub, obj, act string, res bool, reason string) {
+	t.Helper()
+	myRes, myReason, err := e.EnforceWithReason(sub, obj, act)
+	if err != nil {
+		t.Errorf("EnforceWithReason Error: %s", err)
+		return
+	}
+	if myRes != res {
+		t.Errorf("%s, %s, %s: %t, supposed to be %t", sub, obj, act, myRes, res)
+	}
+	if myReason != reason {
+		t.Errorf("%s, %s, %s: reason %q, supposed to be %q", sub, obj, act, myReason, reason)
+	}
+}
+
+func TestEnforceWithReason(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_message_model.conf", "examples/rbac_with_message_policy.csv")
+
+	// The message of the deciding rule is templated with the request and the rule.
+	testEnforceWithReason(t, e, "bob", "/data2/1", "write", false, "bob cannot modify /data2/1 during the audit")
+	testEnforceWithReason(t, e, "cathy", "/data2/1", "write", false, "no role or rule applies to subject cathy")
+	_, _ = e.AddGroupingPolicy("cathy", "data_admin")
+	testEnforceWithReason(t, e, "cathy", "/data2/1", "write", true, "cathy can write /data2/1 as data_admin")
+
+	// A rule without message.
+	testEnforceWithReason(t, e, "alice", "/data1/1", "read", true, "")
+	testEnforceWithReason(t, e, "bob", "/data2/report", "read", false, "denied by rule: bob, /data2/report, read, deny, ")
+
+	// The reason of a not applicable request is synthesized from what was missing.
+	testEnforceWithReason(t, e, "alice", "/data2/1", "read", false, "no rule of subject alice matches object /data2/1")
+	testEnforceWithReason(t, e, "alice", "/data1/1", "write", false, "no rule of subject alice allows action write")
+	testEnforceWithReason(t, e, "bob", "/data3/1", "read", false, "no rule of subject bob matches object /data3/1")
+}
+
+func TestEnforceWithReasonWithoutMessage(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_domains_model.conf", "examples/rbac_with_domains_policy.csv")
+
+	ok, reason, _ := e.EnforceWithReason("alice", "domain2", "data2", "read")
+	if ok || reason != "no role or rule applies to subject alice" {
+		t.Errorf("EnforceWithReason: %t, %q", ok, reason)
+	}
+	ok, reason, _ = e.EnforceWithReason("alice", "domain1", "data1", "read")
+	if !ok || reason != "" {
+		t.Errorf("EnforceWithReason: %t, %q", ok, reason)
+	}
+}
+
+func TestEnforceWithReasonOptionalMessage(t *testing.T) {
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, eft=allow, message=
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = g(r.sub, p.sub) && keyMatch(r.obj, p.obj) && r.act == p.act
+`)
+	a := stringadapter.NewAdapter(`
+p, alice, /data1/*, read
+p, bob, /data2/*, write, deny
+p, bob, /data2/*, read, deny, {r.sub} cannot read {r.obj}
+`)
+	e, err := NewEnforcer(m, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an adapter may append the rules to the policy without their optional fields
+	ast := e.GetModel()["p"]["p"]
+	ast.Policy = append(ast.Policy, []string{"cathy", "/data3/*", "read"}, []string{"cathy", "/data3/*", "write", "deny"})
+
+	// The rules without their optional message get the default message.
+	testEnforceWithReason(t, e, "cathy", "/data3/1", "read", true, "")
+	testEnforceWithReason(t, e, "cathy", "/data3/1", "write", false, "denied by rule: cathy, /data3/*, write, deny, ")
+	testEnforceWithReason(t, e, "cathy", "/data3/1", "delete", false, "no rule of subject cathy allows action delete")
+	testEnforceWithReason(t, e, "alice", "/data1/1", "read", true, "")
+	testEnforceWithReason(t, e, "bob", "/data2/1", "write", false, "denied by rule: bob, /data2/*, write, deny, ")
+	testEnforceWithReason(t, e, "bob", "/data2/1", "read", false, "bob cannot read /data2/1")
+	testEnforceWithReason(t, e, "alice", "/data2/1", "read", false, "no rule of subject alice matches object /data2/1")
+}
+
+func TestEnforceWithReasonContext(t *testing.T) {
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+r2 = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, eft=allow, message=
+p2 = sub, obj, act, eft=allow, message=
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+e2 = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+m2 = r2.sub == p2.sub && r2.obj == p2.obj && r2.act == p2.act ? 1 : 0
+`)
+	a := stringadapter.NewAdapter(`
+p2, alice, data1, read
+p2, alice, data1, write, deny, {r.sub} cannot modify {r.obj} with {p.act}
+`)
+	e, err := NewEnforcer(m, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request values of r2 and the float result of m2 make the reasons.
+	ctx := NewEnforceContext("2")
+	for _, tc := range []struct {
+		sub, obj, act string
+		res           bool
+		reason        string
+	}{
+		{"alice", "data1", "read", true, ""},
+		{"alice", "data1", "write", false, "alice cannot modify data1 with write"},
+		{"alice", "data1", "delete", false, "no rule of subject alice allows action delete"},
+		{"alice", "data2", "read", false, "no rule of subject alice matches object data2"},
+		{"bob", "data1", "read", false, "no role or rule applies to subject bob"},
+	} {
+		res, reason, err := e.EnforceWithReason(ctx, tc.sub, tc.obj, tc.act)
+		if err != nil {
+			t.Errorf("EnforceWithReason Error: %s", err)
+			continue
+		}
+		if res != tc.res {
+			t.Errorf("%s, %s, %s: %t, supposed to be %t", tc.sub, tc.obj, tc.act, res, tc.res)
+		}
+		if reason != tc.reason {
+			t.Errorf("%s, %s, %s: reason %q, supposed to be %q", tc.sub, tc.obj, tc.act, reason, tc.reason)
+		}
+	}
+}
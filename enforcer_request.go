@@ -0,0 +1,102 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// requestFieldTag is the struct tag naming the request token of a field, e.g. `casbin:"sub"`.
+const requestFieldTag = "casbin"
+
+// resolveNamedRequest converts a named request to the request values of rType, in the order of its tokens.
+// A named request is a single value passed instead of the request values, either a struct having fields
+// tagged `casbin:"<token>"`, or a map keyed by token names when the request definition has several tokens,
+// e.g. map[string]interface{}{"sub": "alice", "obj": "data1", "act": "read"} for "r = sub, obj, act".
+// Other requests are returned as is.
+func resolveNamedRequest(tokens []string, rType string, rvals []interface{}) ([]interface{}, error) {
+	if len(rvals) != 1 {
+		return rvals, nil
+	}
+	fields, ok := namedRequestFields(rvals[0], len(tokens) > 1)
+	if !ok {
+		return rvals, nil
+	}
+
+	res := make([]interface{}, len(tokens))
+	known := make(map[string]struct{}, len(tokens))
+	for i, token := range tokens {
+		name := strings.TrimPrefix(token, rType+"_")
+		value, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("missing request field %q of request definition %s", name, rType)
+		}
+		res[i] = value
+		known[name] = struct{}{}
+	}
+
+	var unknown []string
+	for name := range fields {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown request fields %q of request definition %s", unknown, rType)
+	}
+	return res, nil
+}
+
+// namedRequestFields returns the fields of a named request keyed by token name,
+// maps are only considered named requests when allowMap is true.
+func namedRequestFields(rval interface{}, allowMap bool) (map[string]interface{}, bool) {
+	v := reflect.ValueOf(rval)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if !allowMap || v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		fields := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			fields[iter.Key().String()] = iter.Value().Interface()
+		}
+		return fields, true
+	case reflect.Struct:
+		var fields map[string]interface{}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name := strings.Split(field.Tag.Get(requestFieldTag), ",")[0]
+			if name == "" || name == "-" || field.PkgPath != "" {
+				continue
+			}
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			fields[name] = v.Field(i).Interface()
+		}
+		return fields, fields != nil
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+)
+
+type testNamedRequest struct {
+	Subject string `casbin:"sub"`
+	Object  string `casbin:"obj"`
+	Action  string `casbin:"act"`
+	Comment string
+}
+
+func TestEnforceNamedRequest(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	testBatchEnforce(t, e, [][]interface{}{
+		{map[string]interface{}{"sub": "alice", "obj": "data1", "act": "read"}},
+		{map[string]interface{}{"act": "write", "obj": "data1", "sub": "alice"}},
+		{map[string]string{"sub": "alice", "obj": "data2", "act": "write"}},
+		{testNamedRequest{Subject: "bob", Object: "data2", Action: "write"}},
+		{&testNamedRequest{Subject: "bob", Object: "data1", Action: "read", Comment: "ignored"}},
+	}, []bool{
+		true, false, true, true, false,
+	})
+
+	_, err := e.Enforce(map[string]interface{}{"sub": "alice", "act": "read"})
+	if err == nil || !strings.Contains(err.Error(), `missing request field "obj"`) {
+		t.Errorf("a missing field should fail, got %v", err)
+	}
+	_, err = e.Enforce(map[string]interface{}{"sub": "alice", "obj": "data1", "act": "read", "dom": "domain1", "env": "prod"})
+	if err == nil || !strings.Contains(err.Error(), `unknown request fields ["dom" "env"]`) {
+		t.Errorf("unknown fields should fail, got %v", err)
+	}
+}
+
+func TestEnforceNamedRequestWithContext(t *testing.T) {
+	e, _ := NewEnforcer("examples/multiple_policy_definitions_model.conf", "examples/multiple_policy_definitions_policy.csv")
+	enforceContext := NewEnforceContext("2")
+	enforceContext.EType = "e"
+
+	testBatchEnforce(t, e, [][]interface{}{
+		{enforceContext, map[string]interface{}{"sub": struct{ Age int }{Age: 70}, "obj": "/data1", "act": "read"}},
+		{enforceContext, map[string]interface{}{"sub": struct{ Age int }{Age: 30}, "obj": "/data1", "act": "read"}},
+	}, []bool{
+		false, true,
+	})
+}
+
+func TestEnforceNamedRequestSingleToken(t *testing.T) {
+	text := `
+[request_definition]
+r = sub
+
+[policy_definition]
+p = sub
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub.Name == p.sub
+`
+	m, _ := model.NewModelFromString(text)
+	e, _ := NewEnforcer(m)
+	_, _ = e.AddPolicy("alice")
+
+	// A map is the value of the single token, a tagged struct is a named request.
+	testBatchEnforce(t, e, [][]interface{}{
+		{map[string]interface{}{"Name": "alice"}},
+		{struct {
+			Sub map[string]interface{} `casbin:"sub"`
+		}{Sub: map[string]interface{}{"Name": "alice"}}},
+	}, []bool{
+		true, true,
+	})
+}
@@ -15,12 +15,15 @@
 package casbin
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/casbin/govaluate"
 
+	"github.com/casbin/casbin/v3/effector"
+	"github.com/casbin/casbin/v3/model"
 	"github.com/casbin/casbin/v3/persist"
 	"github.com/casbin/casbin/v3/rbac"
 )
@@ -121,11 +124,30 @@ func (e *SyncedEnforcer) StopAutoLoadPolicy() {
 	}
 }
 
-// SetWatcher sets the current watcher.
+// SetWatcher sets the current watcher, with the update callback returned by NewWatcherCallback
+// applying the changes under the lock of the enforcer, see Enforcer.SetWatcher.
 func (e *SyncedEnforcer) SetWatcher(watcher persist.Watcher) error {
 	e.m.Lock()
 	defer e.m.Unlock()
-	return e.Enforcer.SetWatcher(watcher)
+	e.setWatcher(watcher)
+	if !setsWatcherCallback(watcher) {
+		return nil
+	}
+	return watcher.SetUpdateCallback(NewWatcherCallback(e))
+}
+
+// SetInstanceID sets the instance ID of the enforcer.
+func (e *SyncedEnforcer) SetInstanceID(id string) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.Enforcer.SetInstanceID(id)
+}
+
+// GetInstanceID gets the instance ID of the enforcer.
+func (e *SyncedEnforcer) GetInstanceID() string {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.GetInstanceID()
 }
 
 // LoadModel reloads the model from the model CONF file.
@@ -187,6 +209,20 @@ func (e *SyncedEnforcer) BuildRoleLinks() error {
 	return e.Enforcer.BuildRoleLinks()
 }
 
+// RegisterLinkConditionFunc registers a link condition function by name for the grouping policy "g".
+func (e *SyncedEnforcer) RegisterLinkConditionFunc(name string, fn rbac.LinkConditionFunc) bool {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return e.Enforcer.RegisterLinkConditionFunc(name, fn)
+}
+
+// RegisterNamedLinkConditionFunc registers a link condition function by name for the grouping policy ptype.
+func (e *SyncedEnforcer) RegisterNamedLinkConditionFunc(ptype, name string, fn rbac.LinkConditionFunc) bool {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return e.Enforcer.RegisterNamedLinkConditionFunc(ptype, name, fn)
+}
+
 // Enforce decides whether a "subject" can access a "object" with the operation "action", input parameters are usually: (sub, obj, act).
 func (e *SyncedEnforcer) Enforce(rvals ...interface{}) (bool, error) {
 	e.m.RLock()
@@ -194,6 +230,14 @@ func (e *SyncedEnforcer) Enforce(rvals ...interface{}) (bool, error) {
 	return e.Enforcer.Enforce(rvals...)
 }
 
+// EnforceWithRoles decides whether a "subject" can access a "object" with the operation "action",
+// taking into account the roles asserted for this request only.
+func (e *SyncedEnforcer) EnforceWithRoles(roles RequestRoles, rvals ...interface{}) (bool, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.EnforceWithRoles(roles, rvals...)
+}
+
 // EnforceWithMatcher use a custom matcher to decides whether a "subject" can access a "object" with the operation "action", input parameters are usually: (matcher, sub, obj, act), use model matcher by default when matcher is "".
 func (e *SyncedEnforcer) EnforceWithMatcher(matcher string, rvals ...interface{}) (bool, error) {
 	e.m.RLock()
@@ -201,6 +245,22 @@ func (e *SyncedEnforcer) EnforceWithMatcher(matcher string, rvals ...interface{}
 	return e.Enforcer.EnforceWithMatcher(matcher, rvals...)
 }
 
+// EnforceWithEffect decides whether a "subject" can access a "object" with the operation "action" and returns the
+// merged effect, see Enforcer.EnforceWithEffect.
+func (e *SyncedEnforcer) EnforceWithEffect(rvals ...interface{}) (effector.Effect, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.EnforceWithEffect(rvals...)
+}
+
+// EnforceWithReason decides whether a "subject" can access a "object" with the operation "action"
+// and returns a human-readable reason for the decision, see Enforcer.EnforceWithReason.
+func (e *SyncedEnforcer) EnforceWithReason(rvals ...interface{}) (bool, string, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.EnforceWithReason(rvals...)
+}
+
 // EnforceEx explain enforcement by informing matched rules.
 func (e *SyncedEnforcer) EnforceEx(rvals ...interface{}) (bool, []string, error) {
 	e.m.RLock()
@@ -208,6 +268,14 @@ func (e *SyncedEnforcer) EnforceEx(rvals ...interface{}) (bool, []string, error)
 	return e.Enforcer.EnforceEx(rvals...)
 }
 
+// EnforceExWithObligations explains enforcement by informing matched rules and returns the obligations and advice
+// of the rules that applied to the decision.
+func (e *SyncedEnforcer) EnforceExWithObligations(rvals ...interface{}) (bool, []string, Obligations, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.EnforceExWithObligations(rvals...)
+}
+
 // EnforceExWithMatcher use a custom matcher and explain enforcement by informing matched rules.
 func (e *SyncedEnforcer) EnforceExWithMatcher(matcher string, rvals ...interface{}) (bool, []string, error) {
 	e.m.RLock()
@@ -229,6 +297,77 @@ func (e *SyncedEnforcer) BatchEnforceWithMatcher(matcher string, requests [][]in
 	return e.Enforcer.BatchEnforceWithMatcher(matcher, requests)
 }
 
+// EnforceWithContext decides whether a "subject" can access a "object" with the operation "action",
+// ctx is passed to the context-aware functions and aborts the enforcement when it is done.
+func (e *SyncedEnforcer) EnforceWithContext(ctx context.Context, rvals ...interface{}) (bool, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.EnforceWithContext(ctx, rvals...)
+}
+
+// EnforceExWithContext explain enforcement by informing matched rules, using ctx as EnforceWithContext does.
+func (e *SyncedEnforcer) EnforceExWithContext(ctx context.Context, rvals ...interface{}) (bool, []string, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.EnforceExWithContext(ctx, rvals...)
+}
+
+// BatchEnforceWithContext enforce in batches, using ctx as EnforceWithContext does.
+func (e *SyncedEnforcer) BatchEnforceWithContext(ctx context.Context, requests [][]interface{}) ([]bool, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.BatchEnforceWithContext(ctx, requests)
+}
+
+// ParallelBatchEnforce enforces the requests concurrently with at most workers goroutines.
+func (e *SyncedEnforcer) ParallelBatchEnforce(requests [][]interface{}, workers int) ([]bool, []error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.ParallelBatchEnforce(requests, workers)
+}
+
+// ParallelBatchEnforceWithContext is ParallelBatchEnforce using ctx as EnforceWithContext does.
+func (e *SyncedEnforcer) ParallelBatchEnforceWithContext(ctx context.Context, requests [][]interface{}, workers int) ([]bool, []error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.ParallelBatchEnforceWithContext(ctx, requests, workers)
+}
+
+// GetAllowedActions returns the actions that subject can perform on object.
+func (e *SyncedEnforcer) GetAllowedActions(subject string, object string, domain ...string) ([]string, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.GetAllowedActions(subject, object, domain...)
+}
+
+// FilterAllowed returns the objects on which subject can perform action.
+func (e *SyncedEnforcer) FilterAllowed(subject string, action string, objects []string, domain ...string) ([]string, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.FilterAllowed(subject, action, objects, domain...)
+}
+
+// GetNamedAllowedActions returns the actions that subject can perform on object with the definitions of enforceContext.
+func (e *SyncedEnforcer) GetNamedAllowedActions(enforceContext EnforceContext, subject string, object string, domain ...string) ([]string, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.GetNamedAllowedActions(enforceContext, subject, object, domain...)
+}
+
+// FilterNamedAllowed returns the objects on which subject can perform action with the definitions of enforceContext.
+func (e *SyncedEnforcer) FilterNamedAllowed(enforceContext EnforceContext, subject string, action string, objects []string, domain ...string) ([]string, error) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.FilterNamedAllowed(enforceContext, subject, action, objects, domain...)
+}
+
+// ValidatePolicies returns all the violations of the policy schemas by the current policy.
+func (e *SyncedEnforcer) ValidatePolicies() []error {
+	e.m.RLock()
+	defer e.m.RUnlock()
+	return e.Enforcer.ValidatePolicies()
+}
+
 // GetAllSubjects gets the list of subjects that show up in the current policy.
 func (e *SyncedEnforcer) GetAllSubjects() ([]string, error) {
 	e.m.RLock()
@@ -623,6 +762,20 @@ func (e *SyncedEnforcer) UpdateNamedGroupingPolicies(ptype string, oldRules [][]
 	return e.Enforcer.UpdateNamedGroupingPolicies(ptype, oldRules, newRules)
 }
 
+// UpdateFilteredGroupingPolicies replaces the role inheritance rules that match the filter with newRules.
+func (e *SyncedEnforcer) UpdateFilteredGroupingPolicies(newRules [][]string, fieldIndex int, fieldValues ...string) (bool, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return e.Enforcer.UpdateFilteredGroupingPolicies(newRules, fieldIndex, fieldValues...)
+}
+
+// UpdateFilteredNamedGroupingPolicies replaces the named role inheritance rules that match the filter with newRules.
+func (e *SyncedEnforcer) UpdateFilteredNamedGroupingPolicies(ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) (bool, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return e.Enforcer.UpdateFilteredNamedGroupingPolicies(ptype, newRules, fieldIndex, fieldValues...)
+}
+
 // RemoveFilteredNamedGroupingPolicy removes a role inheritance rule from the current named policy, field filters can be specified.
 func (e *SyncedEnforcer) RemoveFilteredNamedGroupingPolicy(ptype string, fieldIndex int, fieldValues ...string) (bool, error) {
 	e.m.Lock()
@@ -637,6 +790,13 @@ func (e *SyncedEnforcer) AddFunction(name string, function govaluate.ExpressionF
 	e.Enforcer.AddFunction(name, function)
 }
 
+// AddContextFunction adds a customized function that receives the context of the enforcement.
+func (e *SyncedEnforcer) AddContextFunction(name string, function model.ContextFunction) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.Enforcer.AddContextFunction(name, function)
+}
+
 func (e *SyncedEnforcer) SelfAddPolicy(sec string, ptype string, rule []string) (bool, error) {
 	e.m.Lock()
 	defer e.m.Unlock()
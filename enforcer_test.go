@@ -19,7 +19,9 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/casbin/casbin/v3/constant"
 	"github.com/casbin/casbin/v3/detector"
+	"github.com/casbin/casbin/v3/effector"
 	"github.com/casbin/casbin/v3/model"
 	fileadapter "github.com/casbin/casbin/v3/persist/file-adapter"
 	"github.com/casbin/casbin/v3/util"
@@ -801,3 +803,60 @@ func TestEnforcerSetDetectors(t *testing.T) {
 		t.Errorf("Expected no error with multiple detectors, but got: %v", err)
 	}
 }
+
+func testEnforceWithEffect(t *testing.T, e *Enforcer, sub, obj, act string, res effector.Effect) {
+	t.Helper()
+	myRes, err := e.EnforceWithEffect(sub, obj, act)
+	if err != nil {
+		t.Errorf("EnforceWithEffect Error: %s", err)
+	} else if myRes != res {
+		t.Errorf("%s, %s, %s: %s, supposed to be %s", sub, obj, act, myRes, res)
+	}
+}
+
+func TestEnforceWithStepUpEffects(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_step_up_model.conf", "examples/rbac_with_step_up_policy.csv")
+
+	// Deny > Challenge > Audit > Allow under allow-and-deny.
+	testEnforceWithEffect(t, e, "alice", "data1", "read", effector.Allow)
+	testEnforceWithEffect(t, e, "alice", "data1", "write", effector.Challenge)
+	testEnforceWithEffect(t, e, "alice", "data1", "delete", effector.Challenge)
+	testEnforceWithEffect(t, e, "alice", "data2", "read", effector.Audit)
+	testEnforceWithEffect(t, e, "bob", "data1", "delete", effector.Deny)
+	testEnforceWithEffect(t, e, "bob", "data2", "read", effector.Indeterminate)
+
+	// A challenge is not allowed by Enforce, an audit is.
+	testEnforce(t, e, "alice", "data1", "write", false)
+	testEnforce(t, e, "alice", "data2", "read", true)
+
+	_, explain, _ := e.EnforceEx("alice", "data1", "write")
+	if !util.ArrayEquals(explain, []string{"data_admin", "data1", "write", "challenge"}) {
+		t.Errorf("EnforceEx: %v", explain)
+	}
+
+	// Deny > Challenge > Audit > Allow under deny-override.
+	e.GetModel()["e"]["e"].Value = constant.DenyOverrideEffect
+	testEnforceWithEffect(t, e, "alice", "data1", "delete", effector.Challenge)
+	testEnforceWithEffect(t, e, "bob", "data1", "delete", effector.Deny)
+	testEnforceWithEffect(t, e, "bob", "data2", "read", effector.Allow)
+
+	// Allow > Audit > Challenge under allow-override.
+	e.GetModel()["e"]["e"].Value = constant.AllowOverrideEffect
+	testEnforceWithEffect(t, e, "alice", "data1", "delete", effector.Allow)
+	testEnforceWithEffect(t, e, "alice", "data2", "read", effector.Audit)
+	_, _ = e.RemovePolicy("data_admin", "data1", "write", "allow")
+	testEnforceWithEffect(t, e, "alice", "data1", "write", effector.Challenge)
+
+	e.EnableEnforce(false)
+	testEnforceWithEffect(t, e, "bob", "data1", "delete", effector.Allow)
+}
+
+func TestEnforceWithStepUpEffectsPriority(t *testing.T) {
+	e, _ := NewEnforcer("examples/priority_model_explicit.conf", "examples/priority_policy_explicit.csv")
+	_, _ = e.AddPolicy("5", "data2_allow_group", "data2", "write", "challenge")
+
+	// The effect of the matched rule with the highest priority applies.
+	testEnforceWithEffect(t, e, "bob", "data2", "write", effector.Challenge)
+	testEnforceWithEffect(t, e, "bob", "data2", "read", effector.Deny)
+	testEnforceWithEffect(t, e, "alice", "data1", "write", effector.Allow)
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrEvalRuleViolation is matched by errors.Is for every EvalRuleViolationError.
+var ErrEvalRuleViolation = errors.New("eval rule violation")
+
+// EvalRuleViolationError represents an eval() rule violating the eval policy of its policy type.
+type EvalRuleViolationError struct {
+	PType   string
+	Rule    string
+	Message string
+}
+
+func (e *EvalRuleViolationError) Error() string {
+	return fmt.Sprintf("eval rule violation [%s] %q: %s", e.PType, e.Rule, e.Message)
+}
+
+// Is reports whether target is ErrEvalRuleViolation.
+func (e *EvalRuleViolationError) Is(target error) bool {
+	return target == ErrEvalRuleViolation
+}
+
+// NewEvalRuleViolationError creates a new eval rule violation error.
+func NewEvalRuleViolationError(ptype, rule, message string) error {
+	return &EvalRuleViolationError{
+		PType:   ptype,
+		Rule:    rule,
+		Message: message,
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Global errors for policy schemas defined here.
+var (
+	ErrPolicySchemaViolation         = errors.New("policy schema violation")
+	ErrInvalidPolicySchemaDefinition = errors.New("invalid policy schema definition")
+)
+
+// PolicySchemaViolationError represents a field of a policy rule violating the schema of its policy type.
+type PolicySchemaViolationError struct {
+	PType   string
+	Field   string
+	Value   string
+	Rule    []string
+	Message string
+}
+
+func (e *PolicySchemaViolationError) Error() string {
+	return fmt.Sprintf("policy schema violation [%s.%s] %q: %s, rule: %v", e.PType, e.Field, e.Value, e.Message, e.Rule)
+}
+
+// Is reports whether target is ErrPolicySchemaViolation.
+func (e *PolicySchemaViolationError) Is(target error) bool {
+	return target == ErrPolicySchemaViolation
+}
+
+// NewPolicySchemaViolationError creates a new policy schema violation error.
+func NewPolicySchemaViolationError(ptype, field, value string, rule []string, message string) error {
+	return &PolicySchemaViolationError{
+		PType:   ptype,
+		Field:   field,
+		Value:   value,
+		Rule:    rule,
+		Message: message,
+	}
+}
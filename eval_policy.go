@@ -0,0 +1,182 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	Err "github.com/casbin/casbin/v3/errors"
+	"github.com/casbin/casbin/v3/util"
+)
+
+var requestAttributeRegex = regexp.MustCompile(`^r[0-9]*[._]`)
+
+// EvalPolicy restricts the rules stored in the policy and evaluated by eval(), e.g. rules authored by tenant admins.
+type EvalPolicy struct {
+	// Functions are the functions a rule may call, e.g. "keyMatch". All functions are allowed when nil.
+	Functions []string
+	// Attributes are the request attributes a rule may reference, e.g. "r.sub.Age". An attribute also allows
+	// its nested attributes, e.g. "r.sub" allows "r.sub.Age". All attributes are allowed when nil.
+	Attributes []string
+	// MaxLength is the maximum length of a rule, unlimited when 0.
+	MaxLength int
+	// MaxDepth is the maximum nesting depth of the parentheses and brackets of a rule, unlimited when 0.
+	MaxDepth int
+}
+
+// SetEvalPolicy restricts the rules of ptype evaluated by eval().
+// Rules violating the policy are rejected when they are added or updated, and fail when they are evaluated,
+// e.g. when they were loaded from the adapter, see SetMatcherErrorPolicy.
+func (e *Enforcer) SetEvalPolicy(ptype string, policy EvalPolicy) {
+	if e.evalPolicies == nil {
+		e.evalPolicies = make(map[string]*EvalPolicy)
+	}
+	e.evalPolicies[ptype] = &policy
+	e.invalidateMatcherMap()
+}
+
+// RemoveEvalPolicy removes the restrictions of the rules of ptype evaluated by eval().
+func (e *Enforcer) RemoveEvalPolicy(ptype string) {
+	delete(e.evalPolicies, ptype)
+	e.invalidateMatcherMap()
+}
+
+// validateEvalRules returns an error if the eval() rule of a policy rule of ptype violates the eval policy of ptype.
+func (e *Enforcer) validateEvalRules(ptype string, rules [][]string) error {
+	policy := e.evalPolicies[ptype]
+	if policy == nil {
+		return nil
+	}
+	indexes := e.getEvalFieldIndexes(ptype)
+	for _, rule := range rules {
+		for _, i := range indexes {
+			if i < len(rule) {
+				if err := policy.validate(ptype, rule[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validate returns an error if rule violates the policy.
+func (policy *EvalPolicy) validate(ptype string, rule string) error {
+	if policy.MaxLength > 0 && len(rule) > policy.MaxLength {
+		return Err.NewEvalRuleViolationError(ptype, rule, fmt.Sprintf("length %d exceeds %d", len(rule), policy.MaxLength))
+	}
+
+	functions, attributes, depth, err := scanEvalRule(rule)
+	if err != nil {
+		return Err.NewEvalRuleViolationError(ptype, rule, err.Error())
+	}
+	if policy.MaxDepth > 0 && depth > policy.MaxDepth {
+		return Err.NewEvalRuleViolationError(ptype, rule, fmt.Sprintf("depth %d exceeds %d", depth, policy.MaxDepth))
+	}
+	if policy.Functions != nil {
+		for _, function := range functions {
+			if !policy.allowsFunction(function) {
+				return Err.NewEvalRuleViolationError(ptype, rule, fmt.Sprintf("function %s is not allowed", function))
+			}
+		}
+	}
+	if policy.Attributes != nil {
+		for _, attribute := range attributes {
+			if !policy.allowsAttribute(attribute) {
+				return Err.NewEvalRuleViolationError(ptype, rule, fmt.Sprintf("attribute %s is not allowed", attribute))
+			}
+		}
+	}
+	return nil
+}
+
+// allowsFunction reports whether the function is one of the allowed functions.
+func (policy *EvalPolicy) allowsFunction(function string) bool {
+	for _, allowed := range policy.Functions {
+		if function == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAttribute reports whether the request attribute is one of the allowed attributes or nested in one of them.
+func (policy *EvalPolicy) allowsAttribute(attribute string) bool {
+	name := util.EscapeAssertion(attribute)
+	for _, allowed := range policy.Attributes {
+		allowed = util.EscapeAssertion(allowed)
+		if name == allowed || strings.HasPrefix(name, allowed+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// scanEvalRule returns the functions and methods called and the request attributes referenced by rule,
+// and the maximum nesting depth of its parentheses and brackets. String literals are skipped.
+// It returns an error if rule references the parameters passed by the matcher to eval().
+func scanEvalRule(rule string) ([]string, []string, int, error) {
+	var functions, attributes []string
+	depth, maxDepth := 0, 0
+	for i := 0; i < len(rule); {
+		c := rule[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(rule) && rule[end] != c {
+				if rule[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rule) {
+				return nil, nil, 0, fmt.Errorf("unterminated string literal at %d", i)
+			}
+			i = end + 1
+		case c == '(' || c == '[':
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			i++
+		case c == ')' || c == ']':
+			depth--
+			i++
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i + 1
+			for end < len(rule) && (rule[end] == '_' || rule[end] == '.' || rule[end] >= 'a' && rule[end] <= 'z' ||
+				rule[end] >= 'A' && rule[end] <= 'Z' || rule[end] >= '0' && rule[end] <= '9') {
+				end++
+			}
+			name := rule[i:end]
+			if strings.HasPrefix(name, evalParametersName) {
+				return nil, nil, 0, fmt.Errorf("%s is reserved", evalParametersName)
+			}
+			if requestAttributeRegex.MatchString(name) {
+				attributes = append(attributes, name)
+			}
+			// A call of a method, e.g. r.sub.IsAdmin(), is a function call as well.
+			if strings.HasPrefix(strings.TrimLeft(rule[end:], " \t"), "(") {
+				functions = append(functions, name)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return functions, attributes, maxDepth, nil
+}
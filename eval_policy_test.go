@@ -0,0 +1,117 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	Err "github.com/casbin/casbin/v3/errors"
+)
+
+func TestEvalPolicyValidate(t *testing.T) {
+	policy := EvalPolicy{
+		Functions:  []string{"keyMatch"},
+		Attributes: []string{"r.sub.Age", "r.obj"},
+		MaxLength:  60,
+		MaxDepth:   2,
+	}
+
+	valid := []string{
+		"r.sub.Age > 18",
+		"r_sub.Age > 18 && keyMatch(r.obj, '/data/*')",
+		"(r.sub.Age > 18 || (r.sub.Age < 5))",
+		"r.sub.Age > 18 && 'r.sub.Name(' != ''",
+	}
+	for _, rule := range valid {
+		if err := policy.validate("p", rule); err != nil {
+			t.Errorf("%q should be valid: %v", rule, err)
+		}
+	}
+
+	invalid := []string{
+		"regexMatch(r.obj, '.*')",
+		"r.sub.Name == 'alice'",
+		"r.act == 'read'",
+		"((((r.sub.Age > 18))))",
+		"r.sub.Age > 18 && r.sub.Age > 18 && r.sub.Age > 18 && r.sub.Age > 18",
+		"r.sub.Age > 18 && 'unterminated",
+		"r.sub.Age.String() == '18'",
+		"r.obj.Delete ()",
+		"casbinEvalParameters.r_sub.Age > 18",
+	}
+	for _, rule := range invalid {
+		err := policy.validate("p", rule)
+		if !errors.Is(err, Err.ErrEvalRuleViolation) {
+			t.Errorf("%q should be invalid, got %v", rule, err)
+		}
+	}
+}
+
+func TestSetEvalPolicy(t *testing.T) {
+	e, _ := NewEnforcer("examples/abac_rule_model.conf", "examples/abac_rule_policy.csv")
+	sub := newTestSubject("alice", 70)
+	e.SetEvalPolicy("p", EvalPolicy{Attributes: []string{"r.sub.Age"}, Functions: []string{}})
+
+	// Violating rules are rejected when they are added or updated.
+	if ok, err := e.AddPolicy("r.sub.Name == 'alice'", "/data3", "read"); ok || !errors.Is(err, Err.ErrEvalRuleViolation) {
+		t.Errorf("AddPolicy: %t, %v", ok, err)
+	}
+	if ok, err := e.AddPolicies([][]string{{"r.sub.Age > 65", "/data3", "read"}, {"keyMatch(r.obj, '/*')", "/data3", "read"}}); ok || err == nil {
+		t.Errorf("AddPolicies: %t, %v", ok, err)
+	}
+	if ok, err := e.UpdatePolicy([]string{"r.sub.Age > 18", "/data1", "read"}, []string{"r.sub.Name != ''", "/data1", "read"}); ok || err == nil {
+		t.Errorf("UpdatePolicy: %t, %v", ok, err)
+	}
+	if ok, err := e.AddPolicy("r.sub.Age > 65", "/data3", "read"); !ok || err != nil {
+		t.Errorf("AddPolicy: %t, %v", ok, err)
+	}
+	testEnforce(t, e, sub, "/data3", "read", true)
+	testEnforce(t, e, sub, "/data1", "read", true)
+
+	// Violating rules loaded from the adapter fail when they are evaluated.
+	e.SetEvalPolicy("p", EvalPolicy{Attributes: []string{"r.sub.Name"}})
+	if _, err := e.Enforce(sub, "/data1", "read"); !errors.Is(err, Err.ErrEvalRuleViolation) {
+		t.Errorf("Enforce should fail on a violating rule, got %v", err)
+	}
+	e.SetMatcherErrorPolicy(MatcherErrorSkip)
+	testEnforce(t, e, sub, "/data1", "read", false)
+
+	e.RemoveEvalPolicy("p")
+	testEnforce(t, e, sub, "/data1", "read", true)
+}
+
+func TestSetEvalPolicyCtx(t *testing.T) {
+	e, err := NewContextEnforcer("examples/abac_rule_model.conf", "examples/abac_rule_policy.csv")
+	if err != nil {
+		t.Fatalf("NewContextEnforcer: %v", err)
+	}
+	e.(*ContextEnforcer).SetEvalPolicy("p", EvalPolicy{Attributes: []string{"r.sub.Age"}, Functions: []string{}})
+	ctx := context.Background()
+
+	if ok, err := e.AddPolicyCtx(ctx, "r.sub.Name == 'alice'", "/data3", "read"); ok || !errors.Is(err, Err.ErrEvalRuleViolation) {
+		t.Errorf("AddPolicyCtx: %t, %v", ok, err)
+	}
+	if ok, err := e.AddPoliciesCtx(ctx, [][]string{{"r.sub.Age > 65", "/data3", "read"}, {"r.sub.Age.String() != ''", "/data3", "read"}}); ok || !errors.Is(err, Err.ErrEvalRuleViolation) {
+		t.Errorf("AddPoliciesCtx: %t, %v", ok, err)
+	}
+	if ok, err := e.UpdatePolicyCtx(ctx, []string{"r.sub.Age > 18", "/data1", "read"}, []string{"r.sub.Name != ''", "/data1", "read"}); ok || !errors.Is(err, Err.ErrEvalRuleViolation) {
+		t.Errorf("UpdatePolicyCtx: %t, %v", ok, err)
+	}
+	if ok, _ := e.HasPolicy("r.sub.Age > 65", "/data3", "read"); ok {
+		t.Error("no rule of an invalid batch should be added")
+	}
+}
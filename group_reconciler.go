@@ -0,0 +1,319 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultGroupReconcileInterval = time.Minute
+
+// GroupReconcilerConfig contains configuration for a GroupReconciler.
+type GroupReconcilerConfig struct {
+	// PType is the grouping policy type to synchronize (default: "g").
+	PType string
+	// Domain is the domain to synchronize, leave it empty for grouping policies without domains.
+	Domain string
+	// Interval between two periodic reconciliations (default: 1 minute).
+	Interval time.Duration
+	// DryRun computes the changes without applying them to the enforcer.
+	DryRun bool
+	// OwnAllGroups makes the source own every group of the ptype and domain, so the rules of the groups
+	// it does not list are removed too. By default only the groups listed by the source are synchronized
+	// and the rules of the other groups, e.g. managed by hand, are kept.
+	OwnAllGroups bool
+	// OnReconcile is called after every reconciliation started by Start, with its result or error.
+	OnReconcile func(result *GroupReconcileResult, err error)
+}
+
+// GroupReconcileResult describes the grouping rules added and removed by a reconciliation.
+type GroupReconcileResult struct {
+	Added   [][]string
+	Removed [][]string
+	DryRun  bool
+}
+
+// GroupReconciler keeps a grouping policy in sync with a GroupSource.
+// The source is authoritative for the groups it lists: for the configured ptype and domain, the rules of these groups
+// missing from the source are removed and the memberships missing from the policy are added,
+// through the normal enforcer path so the adapter, watcher and dispatcher are all notified.
+// See GroupReconcilerConfig.OwnAllGroups to remove the rules of the groups unknown to the source as well.
+type GroupReconciler struct {
+	enforcer IEnforcer
+	source   GroupSource
+	config   GroupReconcilerConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mutex  sync.Mutex
+	// reconciling serializes the reconciliations.
+	reconciling sync.Mutex
+}
+
+// NewGroupReconciler creates a reconciler synchronizing source into the grouping policy of e.
+func NewGroupReconciler(e IEnforcer, source GroupSource, config GroupReconcilerConfig) *GroupReconciler {
+	if config.PType == "" {
+		config.PType = "g"
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultGroupReconcileInterval
+	}
+	return &GroupReconciler{
+		enforcer: e,
+		source:   source,
+		config:   config,
+	}
+}
+
+// Reconcile diffs the source against the grouping policy once and applies the minimal set of changes,
+// unless the reconciler is in dry-run mode.
+// The rules are compared on their member, group and domain, so the condition columns of a rule are kept.
+// The stale rules are replaced with the added memberships in one update, so a member moved to another group
+// is never left without membership, and the remaining rules are then added or removed in one batch.
+func (r *GroupReconciler) Reconcile(ctx context.Context) (*GroupReconcileResult, error) {
+	r.reconciling.Lock()
+	defer r.reconciling.Unlock()
+
+	keyLen, conditionLen, err := r.ruleLength()
+	if err != nil {
+		return nil, err
+	}
+	desired, groups, err := r.desiredRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.currentRules(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupReconcileResult{DryRun: r.config.DryRun}
+	currentSet := make(map[string]struct{}, len(current))
+	for _, rule := range current {
+		currentSet[groupRuleKey(rule, keyLen)] = struct{}{}
+	}
+	desiredSet := make(map[string]struct{}, len(desired))
+	for _, rule := range desired {
+		key := groupRuleKey(rule, keyLen)
+		desiredSet[key] = struct{}{}
+		if _, ok := currentSet[key]; !ok {
+			// a membership of the source has no condition
+			for i := 0; i < conditionLen; i++ {
+				rule = append(rule, "_")
+			}
+			result.Added = append(result.Added, rule)
+		}
+	}
+	for _, rule := range current {
+		if _, ok := desiredSet[groupRuleKey(rule, keyLen)]; !ok {
+			result.Removed = append(result.Removed, rule)
+		}
+	}
+
+	if r.config.DryRun {
+		return result, nil
+	}
+
+	updated := len(result.Added)
+	if len(result.Removed) < updated {
+		updated = len(result.Removed)
+	}
+	if updated > 0 {
+		if _, err = r.enforcer.UpdateNamedGroupingPolicies(r.config.PType, result.Removed[:updated], result.Added[:updated]); err != nil {
+			return result, err
+		}
+	}
+	if len(result.Added) > updated {
+		if _, err = r.enforcer.AddNamedGroupingPolicies(r.config.PType, result.Added[updated:]); err != nil {
+			return result, err
+		}
+	}
+	if len(result.Removed) > updated {
+		if _, err = r.enforcer.RemoveNamedGroupingPolicies(r.config.PType, result.Removed[updated:]); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// ruleLength returns the number of key columns of the rules of the configured ptype, i.e. 2 for member and group,
+// or 3 with the domain, and the number of their condition columns.
+// It returns an error if the configured domain does not fit the role definition.
+func (r *GroupReconciler) ruleLength() (int, int, error) {
+	assertion, err := r.enforcer.GetModel().GetAssertion("g", r.config.PType)
+	if err != nil {
+		return 0, 0, err
+	}
+	keyLen := len(assertion.Tokens)
+	if keyLen > 2 && r.config.Domain == "" {
+		return 0, 0, fmt.Errorf("grouping policy %s has domains, a domain should be configured", r.config.PType)
+	}
+	if keyLen <= 2 && r.config.Domain != "" {
+		return 0, 0, fmt.Errorf("grouping policy %s has no domain, domain %s cannot be configured", r.config.PType, r.config.Domain)
+	}
+	return keyLen, len(assertion.ParamsTokens), nil
+}
+
+// groupRuleKey returns the key columns of a grouping rule.
+func groupRuleKey(rule []string, keyLen int) string {
+	if len(rule) > keyLen {
+		rule = rule[:keyLen]
+	}
+	return strings.Join(rule, ",")
+}
+
+// Start reconciles periodically in a background goroutine until ctx is done or Stop is called.
+// If the source is a WatchableGroupSource, a reconciliation is also triggered on every change.
+// Use a SyncedEnforcer when the enforcer is accessed concurrently with the reconciler.
+func (r *GroupReconciler) Start(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.cancel != nil {
+		return errors.New("group reconciler is already started")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	var changes <-chan struct{}
+	if ws, ok := r.source.(WatchableGroupSource); ok {
+		changes = ws.Changes()
+	}
+
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.reconcileAndReport(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.reconcileAndReport(ctx)
+			case <-changes:
+				r.reconcileAndReport(ctx)
+			}
+		}
+	}(r.done)
+
+	return nil
+}
+
+// Stop stops the background reconciliation and waits for it to finish.
+func (r *GroupReconciler) Stop() {
+	r.mutex.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (r *GroupReconciler) reconcileAndReport(ctx context.Context) {
+	result, err := r.Reconcile(ctx)
+	if r.config.OnReconcile != nil {
+		r.config.OnReconcile(result, err)
+	}
+}
+
+// desiredRules builds the grouping rules described by the source, it also returns the groups listed by the source.
+func (r *GroupReconciler) desiredRules(ctx context.Context) ([][]string, map[string]struct{}, error) {
+	var membership map[string][]string
+	if ss, ok := r.source.(SnapshotGroupSource); ok {
+		snapshot, err := ss.Snapshot(ctx, r.config.Domain)
+		if err != nil {
+			return nil, nil, err
+		}
+		membership = snapshot
+	} else {
+		groups, err := r.source.ListGroups(ctx, r.config.Domain)
+		if err != nil {
+			return nil, nil, err
+		}
+		membership = make(map[string][]string, len(groups))
+		for _, group := range groups {
+			if err = ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			members, err := r.source.ListMembers(ctx, r.config.Domain, group)
+			if err != nil {
+				return nil, nil, err
+			}
+			membership[group] = members
+		}
+	}
+
+	groups := make([]string, 0, len(membership))
+	groupSet := make(map[string]struct{}, len(membership))
+	for group := range membership {
+		groups = append(groups, group)
+		groupSet[group] = struct{}{}
+	}
+	sort.Strings(groups)
+
+	var rules [][]string
+	seen := make(map[string]struct{})
+	for _, group := range groups {
+		for _, member := range membership[group] {
+			rule := []string{member, group}
+			if r.config.Domain != "" {
+				rule = append(rule, r.config.Domain)
+			}
+			key := strings.Join(rule, ",")
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, groupSet, nil
+}
+
+// currentRules returns the grouping rules of the configured ptype and domain,
+// only the rules of the given groups are returned unless the source owns all the groups.
+func (r *GroupReconciler) currentRules(groups map[string]struct{}) ([][]string, error) {
+	var rules [][]string
+	var err error
+	if r.config.Domain == "" {
+		rules, err = r.enforcer.GetNamedGroupingPolicy(r.config.PType)
+	} else {
+		rules, err = r.enforcer.GetFilteredNamedGroupingPolicy(r.config.PType, 2, r.config.Domain)
+	}
+	if err != nil || r.config.OwnAllGroups {
+		return rules, err
+	}
+
+	owned := rules[:0:0]
+	for _, rule := range rules {
+		if _, ok := groups[rule[1]]; ok {
+			owned = append(owned, rule)
+		}
+	}
+	return owned, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v3/util"
+)
+
+func TestGroupReconciler(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	e.EnableAutoSave(false)
+
+	source := NewMemoryGroupSource()
+	source.SetMembers("", "data2_admin", "bob", "cathy")
+
+	r := NewGroupReconciler(e, source, GroupReconcilerConfig{DryRun: true})
+	result, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !util.Array2DEquals([][]string{{"bob", "data2_admin"}, {"cathy", "data2_admin"}}, result.Added) {
+		t.Errorf("Added = %v", result.Added)
+	}
+	if !util.Array2DEquals([][]string{{"alice", "data2_admin"}}, result.Removed) {
+		t.Errorf("Removed = %v", result.Removed)
+	}
+	// A dry run does not touch the policy.
+	testEnforce(t, e, "alice", "data2", "read", true)
+	testEnforce(t, e, "bob", "data2", "read", false)
+
+	r = NewGroupReconciler(e, source, GroupReconcilerConfig{})
+	if _, err = r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	testEnforce(t, e, "alice", "data2", "read", false)
+	testEnforce(t, e, "bob", "data2", "read", true)
+	testEnforce(t, e, "cathy", "data2", "read", true)
+
+	// Nothing left to do once in sync.
+	result, _ = r.Reconcile(context.Background())
+	if len(result.Added) != 0 || len(result.Removed) != 0 {
+		t.Errorf("expected no changes, got %v", result)
+	}
+}
+
+func TestGroupReconcilerWithDomain(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_domains_model.conf", "examples/rbac_with_domains_policy.csv")
+	e.EnableAutoSave(false)
+
+	source := NewMemoryGroupSource()
+	source.SetMembers("domain1", "admin", "alice", "cathy")
+
+	r := NewGroupReconciler(e, source, GroupReconcilerConfig{Domain: "domain1"})
+	result, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !util.Array2DEquals([][]string{{"cathy", "admin", "domain1"}}, result.Added) || len(result.Removed) != 0 {
+		t.Errorf("unexpected result %v", result)
+	}
+	testDomainEnforce(t, e, "cathy", "domain1", "data1", "read", true)
+	// Other domains are left untouched.
+	testDomainEnforce(t, e, "bob", "domain2", "data2", "read", true)
+}
+
+func TestGroupReconcilerWithConditions(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_domain_named_conditions_model.conf", "examples/rbac_with_domain_named_conditions_policy.csv")
+	e.EnableAutoSave(false)
+	e.RegisterLinkConditionFunc("timeMatch", util.TimeMatchFunc)
+
+	source := NewMemoryGroupSource()
+	source.SetMembers("domain1", "data1_admin", "alice", "bob")
+
+	// A domain is required by a grouping policy with domains.
+	r := NewGroupReconciler(e, source, GroupReconcilerConfig{})
+	if _, err := r.Reconcile(context.Background()); err == nil {
+		t.Error("Reconcile() should fail without domain")
+	}
+
+	// The rules are compared without their conditions, which are kept.
+	r = NewGroupReconciler(e, source, GroupReconcilerConfig{Domain: "domain1"})
+	result, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !util.Array2DEquals([][]string{{"bob", "data1_admin", "domain1", "_", "_", "_"}}, result.Added) || len(result.Removed) != 0 {
+		t.Errorf("unexpected result %v", result)
+	}
+	if ok, _ := e.HasGroupingPolicy("alice", "data1_admin", "domain1", "timeMatch", "_", "9999-12-30 00:00:00"); !ok {
+		t.Error("the conditional rule should be kept")
+	}
+	testDomainEnforce(t, e, "alice", "domain1", "data1", "read", true)
+	testDomainEnforce(t, e, "alice", "domain2", "data2", "read", false)
+	testDomainEnforce(t, e, "bob", "domain1", "data1", "read", true)
+}
+
+func TestGroupReconcilerOwnAllGroups(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	e.EnableAutoSave(false)
+	_, _ = e.AddGroupingPolicies([][]string{{"bob", "data1_admin"}, {"cathy", "data1_admin"}})
+
+	source := NewMemoryGroupSource()
+	source.SetMembers("", "data1_admin", "alice", "bob")
+
+	// The groups unknown to the source are left untouched.
+	r := NewGroupReconciler(e, source, GroupReconcilerConfig{})
+	result, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !util.Array2DEquals([][]string{{"alice", "data1_admin"}}, result.Added) ||
+		!util.Array2DEquals([][]string{{"cathy", "data1_admin"}}, result.Removed) {
+		t.Errorf("unexpected result %v", result)
+	}
+	rules, _ := e.GetGroupingPolicy()
+	if !util.Set2DEquals([][]string{{"alice", "data2_admin"}, {"bob", "data1_admin"}, {"alice", "data1_admin"}}, rules) {
+		t.Errorf("grouping policy = %v", rules)
+	}
+
+	r = NewGroupReconciler(e, source, GroupReconcilerConfig{OwnAllGroups: true})
+	result, err = r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 0 || !util.Array2DEquals([][]string{{"alice", "data2_admin"}}, result.Removed) {
+		t.Errorf("unexpected result %v", result)
+	}
+	testEnforce(t, e, "alice", "data2", "read", false)
+	testEnforce(t, e, "alice", "data1", "read", true)
+}
+
+func TestGroupReconcilerConcurrent(t *testing.T) {
+	e, _ := NewSyncedEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	e.EnableAutoSave(false)
+
+	source := NewMemoryGroupSource()
+	source.SetMembers("", "data2_admin", "bob", "cathy")
+	r := NewGroupReconciler(e, source, GroupReconcilerConfig{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.Reconcile(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	rules, _ := e.GetGroupingPolicy()
+	if !util.Array2DEquals([][]string{{"bob", "data2_admin"}, {"cathy", "data2_admin"}}, rules) {
+		t.Errorf("grouping policy = %v", rules)
+	}
+}
+
+func TestGroupReconcilerStart(t *testing.T) {
+	e, _ := NewSyncedEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	e.EnableAutoSave(false)
+
+	source := NewMemoryGroupSource()
+	source.SetMembers("", "data2_admin", "alice")
+
+	reconciled := make(chan *GroupReconcileResult, 10)
+	r := NewGroupReconciler(e, source, GroupReconcilerConfig{
+		Interval: time.Hour,
+		OnReconcile: func(result *GroupReconcileResult, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			reconciled <- result
+		},
+	})
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+	if err := r.Start(context.Background()); err == nil {
+		t.Error("starting twice should fail")
+	}
+
+	source.AddMember("", "data2_admin", "bob")
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-reconciled:
+		case <-deadline:
+			t.Fatal("timed out waiting for the change to be reconciled")
+		}
+		if ok, _ := e.HasGroupingPolicy("bob", "data2_admin"); ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// GroupSource is the interface for external sources of group membership, such as a directory service.
+// domain is "" for grouping policies without domains.
+type GroupSource interface {
+	// ListGroups lists the groups of a domain.
+	ListGroups(ctx context.Context, domain string) ([]string, error)
+	// ListMembers lists the direct members of a group in a domain.
+	ListMembers(ctx context.Context, domain string, group string) ([]string, error)
+}
+
+// SnapshotGroupSource is a GroupSource that can return the whole membership of a domain at once.
+type SnapshotGroupSource interface {
+	GroupSource
+	// Snapshot returns the members of every group of a domain, keyed by group.
+	Snapshot(ctx context.Context, domain string) (map[string][]string, error)
+}
+
+// WatchableGroupSource is a GroupSource that notifies about membership changes.
+type WatchableGroupSource interface {
+	GroupSource
+	// Changes returns a channel that receives a value whenever the membership has changed.
+	Changes() <-chan struct{}
+}
+
+var _ SnapshotGroupSource = &MemoryGroupSource{}
+var _ WatchableGroupSource = &MemoryGroupSource{}
+
+// MemoryGroupSource is an in-memory GroupSource, useful for tests and dry runs.
+type MemoryGroupSource struct {
+	groups  map[string]map[string]map[string]struct{} // domain -> group -> members
+	changes chan struct{}
+	mutex   sync.RWMutex
+}
+
+// NewMemoryGroupSource creates an empty in-memory group source.
+func NewMemoryGroupSource() *MemoryGroupSource {
+	return &MemoryGroupSource{
+		groups:  make(map[string]map[string]map[string]struct{}),
+		changes: make(chan struct{}, 1),
+	}
+}
+
+// SetMembers replaces the members of a group in a domain.
+// A group without members is kept, so its rules will be removed by a reconciler.
+func (s *MemoryGroupSource) SetMembers(domain string, group string, members ...string) {
+	s.mutex.Lock()
+	groupMap := s.getGroupMap(domain)
+	memberSet := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		memberSet[member] = struct{}{}
+	}
+	groupMap[group] = memberSet
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// AddMember adds a member to a group in a domain.
+func (s *MemoryGroupSource) AddMember(domain string, group string, member string) {
+	s.mutex.Lock()
+	groupMap := s.getGroupMap(domain)
+	if groupMap[group] == nil {
+		groupMap[group] = make(map[string]struct{})
+	}
+	groupMap[group][member] = struct{}{}
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// RemoveMember removes a member from a group in a domain.
+func (s *MemoryGroupSource) RemoveMember(domain string, group string, member string) {
+	s.mutex.Lock()
+	if groupMap, ok := s.groups[domain]; ok {
+		delete(groupMap[group], member)
+	}
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// RemoveGroup removes a group and all its members from a domain.
+func (s *MemoryGroupSource) RemoveGroup(domain string, group string) {
+	s.mutex.Lock()
+	if groupMap, ok := s.groups[domain]; ok {
+		delete(groupMap, group)
+	}
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// ListGroups lists the groups of a domain in lexical order.
+func (s *MemoryGroupSource) ListGroups(_ context.Context, domain string) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	groups := make([]string, 0, len(s.groups[domain]))
+	for group := range s.groups[domain] {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// ListMembers lists the members of a group in a domain in lexical order.
+func (s *MemoryGroupSource) ListMembers(_ context.Context, domain string, group string) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.listMembers(domain, group), nil
+}
+
+// Snapshot returns the members of every group of a domain.
+func (s *MemoryGroupSource) Snapshot(_ context.Context, domain string) (map[string][]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshot := make(map[string][]string, len(s.groups[domain]))
+	for group := range s.groups[domain] {
+		snapshot[group] = s.listMembers(domain, group)
+	}
+	return snapshot, nil
+}
+
+// Changes returns a channel that receives a value after every modification.
+func (s *MemoryGroupSource) Changes() <-chan struct{} {
+	return s.changes
+}
+
+func (s *MemoryGroupSource) getGroupMap(domain string) map[string]map[string]struct{} {
+	groupMap, ok := s.groups[domain]
+	if !ok {
+		groupMap = make(map[string]map[string]struct{})
+		s.groups[domain] = groupMap
+	}
+	return groupMap
+}
+
+func (s *MemoryGroupSource) listMembers(domain string, group string) []string {
+	members := make([]string, 0, len(s.groups[domain][group]))
+	for member := range s.groups[domain][group] {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// notify signals a change without blocking, pending signals are coalesced.
+func (s *MemoryGroupSource) notify() {
+	select {
+	case s.changes <- struct{}{}:
+	default:
+	}
+}
@@ -41,8 +41,29 @@ func (e *Enforcer) validateConstraintsForGroupingPolicy() error {
 	return e.model.ValidateConstraints()
 }
 
+// validatePolicyRules returns an error if rules completed by FillPoliciesDefaults miss a required field,
+// violate the policy schema of ptype or the eval policy of ptype. Every write path calls it before touching the adapter.
+func (e *Enforcer) validatePolicyRules(sec string, ptype string, rules [][]string) error {
+	if err := e.model.CheckRequiredFields(sec, ptype, rules); err != nil {
+		return err
+	}
+	if err := e.model.ValidatePolicySchema(sec, ptype, rules); err != nil {
+		return err
+	}
+	if sec == "p" {
+		return e.validateEvalRules(ptype, rules)
+	}
+	return nil
+}
+
 // addPolicy adds a rule to the current policy.
 func (e *Enforcer) addPolicyWithoutNotify(sec string, ptype string, rule []string) (bool, error) {
+	// complete the optional fields, so that the adapter and the model get the same rules
+	rule = e.model.FillPolicyDefaults(sec, ptype, rule)
+	if err := e.validatePolicyRules(sec, ptype, [][]string{rule}); err != nil {
+		return false, err
+	}
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.AddPolicies(sec, ptype, [][]string{rule})
 	}
@@ -65,12 +86,21 @@ func (e *Enforcer) addPolicyWithoutNotify(sec string, ptype string, rule []strin
 		return false, err
 	}
 
+	if sec == "p" {
+		e.compileEvalRules(ptype, [][]string{rule})
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, [][]string{rule})
 		if err != nil {
 			return true, err
 		}
 
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, [][]string{rule})
+		if err != nil {
+			return true, err
+		}
+
 		// Validate constraints after adding grouping policy
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
 			return false, err
@@ -84,6 +114,11 @@ func (e *Enforcer) addPolicyWithoutNotify(sec string, ptype string, rule []strin
 // If autoRemoveRepeat == true, existing rules are automatically filtered
 // Otherwise, false is returned directly.
 func (e *Enforcer) addPoliciesWithoutNotify(sec string, ptype string, rules [][]string, autoRemoveRepeat bool) (bool, error) {
+	rules = e.model.FillPoliciesDefaults(sec, ptype, rules)
+	if err := e.validatePolicyRules(sec, ptype, rules); err != nil {
+		return false, err
+	}
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.AddPolicies(sec, ptype, rules)
 	}
@@ -108,6 +143,10 @@ func (e *Enforcer) addPoliciesWithoutNotify(sec string, ptype string, rules [][]
 		return false, err
 	}
 
+	if sec == "p" {
+		e.compileEvalRules(ptype, rules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, rules)
 		if err != nil {
@@ -130,6 +169,8 @@ func (e *Enforcer) addPoliciesWithoutNotify(sec string, ptype string, rules [][]
 
 // removePolicy removes a rule from the current policy.
 func (e *Enforcer) removePolicyWithoutNotify(sec string, ptype string, rule []string) (bool, error) {
+	rule = e.model.FillPolicyDefaults(sec, ptype, rule)
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.RemovePolicies(sec, ptype, [][]string{rule})
 	}
@@ -147,12 +188,21 @@ func (e *Enforcer) removePolicyWithoutNotify(sec string, ptype string, rule []st
 		return ruleRemoved, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, [][]string{rule})
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, [][]string{rule})
 		if err != nil {
 			return ruleRemoved, err
 		}
 
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, [][]string{rule})
+		if err != nil {
+			return ruleRemoved, err
+		}
+
 		// Validate constraints after removing grouping policy
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
 			return false, err
@@ -163,6 +213,12 @@ func (e *Enforcer) removePolicyWithoutNotify(sec string, ptype string, rule []st
 }
 
 func (e *Enforcer) updatePolicyWithoutNotify(sec string, ptype string, oldRule []string, newRule []string) (bool, error) {
+	oldRule = e.model.FillPolicyDefaults(sec, ptype, oldRule)
+	newRule = e.model.FillPolicyDefaults(sec, ptype, newRule)
+	if err := e.validatePolicyRules(sec, ptype, [][]string{newRule}); err != nil {
+		return false, err
+	}
+
 	if e.dispatcher != nil && e.autoNotifyDispatcher {
 		return true, e.dispatcher.UpdatePolicy(sec, ptype, oldRule, newRule)
 	}
@@ -179,15 +235,28 @@ func (e *Enforcer) updatePolicyWithoutNotify(sec string, ptype string, oldRule [
 		return ruleUpdated, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, [][]string{oldRule})
+		e.compileEvalRules(ptype, [][]string{newRule})
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, [][]string{oldRule}) // remove the old rule
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, [][]string{oldRule})
+		if err != nil {
+			return ruleUpdated, err
+		}
 		err = e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, [][]string{newRule}) // add the new rule
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, [][]string{newRule})
+		if err != nil {
+			return ruleUpdated, err
+		}
 
 		// Validate constraints after updating grouping policy
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
@@ -199,6 +268,12 @@ func (e *Enforcer) updatePolicyWithoutNotify(sec string, ptype string, oldRule [
 }
 
 func (e *Enforcer) updatePoliciesWithoutNotify(sec string, ptype string, oldRules [][]string, newRules [][]string) (bool, error) {
+	oldRules = e.model.FillPoliciesDefaults(sec, ptype, oldRules)
+	newRules = e.model.FillPoliciesDefaults(sec, ptype, newRules)
+	if err := e.validatePolicyRules(sec, ptype, newRules); err != nil {
+		return false, err
+	}
+
 	if len(newRules) != len(oldRules) {
 		return false, fmt.Errorf("the length of oldRules should be equal to the length of newRules, but got the length of oldRules is %d, the length of newRules is %d", len(oldRules), len(newRules))
 	}
@@ -220,15 +295,28 @@ func (e *Enforcer) updatePoliciesWithoutNotify(sec string, ptype string, oldRule
 		return ruleUpdated, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, oldRules)
+		e.compileEvalRules(ptype, newRules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, oldRules) // remove the old rules
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, oldRules)
+		if err != nil {
+			return ruleUpdated, err
+		}
 		err = e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, newRules) // add the new rules
 		if err != nil {
 			return ruleUpdated, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, newRules)
+		if err != nil {
+			return ruleUpdated, err
+		}
 
 		// Validate constraints after updating grouping policies
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
@@ -241,6 +329,8 @@ func (e *Enforcer) updatePoliciesWithoutNotify(sec string, ptype string, oldRule
 
 // removePolicies removes rules from the current policy.
 func (e *Enforcer) removePoliciesWithoutNotify(sec string, ptype string, rules [][]string) (bool, error) {
+	rules = e.model.FillPoliciesDefaults(sec, ptype, rules)
+
 	if hasPolicies, err := e.model.HasPolicies(sec, ptype, rules); !hasPolicies || err != nil {
 		return hasPolicies, err
 	}
@@ -262,11 +352,19 @@ func (e *Enforcer) removePoliciesWithoutNotify(sec string, ptype string, rules [
 		return rulesRemoved, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, rules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, rules)
 		if err != nil {
 			return rulesRemoved, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, rules)
+		if err != nil {
+			return rulesRemoved, err
+		}
 
 		// Validate constraints after removing grouping policies
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
@@ -299,11 +397,19 @@ func (e *Enforcer) removeFilteredPolicyWithoutNotify(sec string, ptype string, f
 		return ruleRemoved, err
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, effects)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, effects)
 		if err != nil {
 			return ruleRemoved, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, effects)
+		if err != nil {
+			return ruleRemoved, err
+		}
 
 		// Validate constraints after removing filtered grouping policies
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
@@ -315,6 +421,11 @@ func (e *Enforcer) removeFilteredPolicyWithoutNotify(sec string, ptype string, f
 }
 
 func (e *Enforcer) updateFilteredPoliciesWithoutNotify(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	newRules = e.model.FillPoliciesDefaults(sec, ptype, newRules)
+	if err := e.validatePolicyRules(sec, ptype, newRules); err != nil {
+		return nil, err
+	}
+
 	var (
 		oldRules [][]string
 		err      error
@@ -355,15 +466,28 @@ func (e *Enforcer) updateFilteredPoliciesWithoutNotify(sec string, ptype string,
 		return make([][]string, 0), nil
 	}
 
+	if sec == "p" {
+		e.forgetEvalRules(ptype, oldRules)
+		e.compileEvalRules(ptype, newRules)
+	}
+
 	if sec == "g" {
 		err := e.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, oldRules) // remove the old rules
 		if err != nil {
 			return oldRules, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyRemove, ptype, oldRules)
+		if err != nil {
+			return oldRules, err
+		}
 		err = e.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, newRules) // add the new rules
 		if err != nil {
 			return oldRules, err
 		}
+		err = e.BuildIncrementalConditionalRoleLinks(model.PolicyAdd, ptype, newRules)
+		if err != nil {
+			return oldRules, err
+		}
 
 		// Validate constraints after updating filtered grouping policies
 		if err := e.validateConstraintsForGroupingPolicy(); err != nil {
@@ -129,6 +129,11 @@ func (l *DefaultLogger) writeLog(entry *LogEntry) error {
 			entry.EventType, entry.Duration)
 	}
 
+	if len(entry.FaultyRules) != 0 {
+		logMessage = strings.TrimSuffix(logMessage, "\n")
+		logMessage = fmt.Sprintf("%s FaultyRules=%v\n", logMessage, entry.FaultyRules)
+	}
+
 	if entry.Error != nil {
 		logMessage = strings.TrimSuffix(logMessage, "\n")
 		logMessage = fmt.Sprintf("%s Error: %v\n", logMessage, entry.Error)
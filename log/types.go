@@ -54,7 +54,11 @@ type LogEntry struct {
 	Rules [][]string
 	// RuleCount is the number of rules affected by the operation.
 	RuleCount int
+	// FaultyRules contains the policy rules whose evaluation failed without aborting the enforcement.
+	FaultyRules [][]string
 
 	// Error contains any error that occurred during the event.
 	Error error
+	// Canceled indicates whether the event was aborted because its context was done.
+	Canceled bool
 }
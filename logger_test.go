@@ -246,3 +246,44 @@ func checkEntryActiveStatus(t *testing.T, entry *log.LogEntry) {
 		// SavePolicy event exists but we're not checking it in this test
 	}
 }
+
+func TestAllowedAPILogEntries(t *testing.T) {
+	e, err := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+
+	var buf bytes.Buffer
+	logger := log.NewDefaultLogger()
+	logger.SetOutput(&buf)
+	var entries []*log.LogEntry
+	err = logger.SetLogCallback(func(entry *log.LogEntry) error {
+		entryCopy := *entry
+		entries = append(entries, &entryCopy)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to set log callback: %v", err)
+	}
+	e.SetLogger(logger)
+
+	// The options passed to enforce before the request values are not logged as the subject.
+	if _, err = e.GetAllowedActions("alice", "data1"); err != nil {
+		t.Fatalf("GetAllowedActions failed: %v", err)
+	}
+	if _, err = e.FilterAllowed("alice", "read", []string{"data1"}); err != nil {
+		t.Fatalf("FilterAllowed failed: %v", err)
+	}
+
+	if len(entries) == 0 {
+		t.Fatal("Expected enforce entries")
+	}
+	for _, entry := range entries {
+		if entry.EventType != log.EventEnforce {
+			continue
+		}
+		if entry.Subject != "alice" || entry.Object != "data1" {
+			t.Errorf("Expected enforce entry of alice on data1, got subject %q and object %q", entry.Subject, entry.Object)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/casbin/casbin/v3/constant"
+	"github.com/casbin/casbin/v3/model"
 	"github.com/casbin/casbin/v3/util"
 	"github.com/casbin/govaluate"
 )
@@ -230,6 +231,13 @@ func (e *Enforcer) HasNamedPolicy(ptype string, params ...interface{}) (bool, er
 	return e.model.HasPolicy("p", ptype, policy)
 }
 
+// ValidatePolicies returns all the violations of the policy schemas declared in the policy_schema section
+// by the current policy, e.g. to check the data stored before a schema was declared.
+// Each violation names its policy type, field and rule, see errors.PolicySchemaViolationError.
+func (e *Enforcer) ValidatePolicies() []error {
+	return e.model.PolicySchemaViolations()
+}
+
 // AddPolicy adds an authorization rule to the current policy.
 // If the rule already exists, the function returns false and the rule will not be added.
 // Otherwise the function returns true by adding the new rule.
@@ -474,14 +482,34 @@ func (e *Enforcer) UpdateNamedGroupingPolicies(ptype string, oldRules [][]string
 	return e.updatePolicies("g", ptype, oldRules, newRules)
 }
 
+// UpdateFilteredGroupingPolicies replaces the role inheritance rules that match the filter with newRules.
+func (e *Enforcer) UpdateFilteredGroupingPolicies(newRules [][]string, fieldIndex int, fieldValues ...string) (bool, error) {
+	return e.UpdateFilteredNamedGroupingPolicies("g", newRules, fieldIndex, fieldValues...)
+}
+
+// UpdateFilteredNamedGroupingPolicies replaces the named role inheritance rules that match the filter with newRules.
+func (e *Enforcer) UpdateFilteredNamedGroupingPolicies(ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) (bool, error) {
+	return e.updateFilteredPolicies("g", ptype, newRules, fieldIndex, fieldValues...)
+}
+
 // RemoveFilteredNamedGroupingPolicy removes a role inheritance rule from the current named policy, field filters can be specified.
 func (e *Enforcer) RemoveFilteredNamedGroupingPolicy(ptype string, fieldIndex int, fieldValues ...string) (bool, error) {
 	return e.removeFilteredPolicy("g", ptype, fieldIndex, fieldValues)
 }
 
 // AddFunction adds a customized function.
+// The compiled matchers and eval() rules are dropped, so that they are compiled again with the function.
 func (e *Enforcer) AddFunction(name string, function govaluate.ExpressionFunction) {
 	e.fm.AddFunction(name, function)
+	e.invalidateMatcherMap()
+}
+
+// AddContextFunction adds a customized function that receives the context passed to EnforceWithContext,
+// or context.Background() for the other enforce methods.
+// The context is passed with the parameters of the request, so the compiled matchers are shared by all contexts.
+func (e *Enforcer) AddContextFunction(name string, function model.ContextFunction) {
+	e.fm.AddContextFunction(name, function)
+	e.invalidateMatcherMap()
 }
 
 func (e *Enforcer) SelfAddPolicy(sec string, ptype string, rule []string) (bool, error) {
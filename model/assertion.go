@@ -35,6 +35,20 @@ type Assertion struct {
 	CondRM          rbac.ConditionalRoleManager
 	FieldIndexMap   map[string]int
 	FieldIndexMutex sync.RWMutex
+
+	// LinkConditionFuncs holds the link condition functions registered by name.
+	// A grouping rule whose first condition column names one of them is bound to it,
+	// and the remaining condition columns are used as its parameters.
+	LinkConditionFuncs map[string]rbac.LinkConditionFunc
+
+	// FieldDefaults holds the default values of the optional trailing fields of a policy definition by index,
+	// e.g. "p = sub, obj, act, eft=allow" gives the field "eft" the default value "allow".
+	FieldDefaults map[int]string
+
+	// PolicySchema holds the field types of a policy definition declared in the policy_schema section,
+	// compiled when the definitions are added to the model, and policySchemaErr the error compiling them.
+	PolicySchema    []*FieldSchema
+	policySchemaErr error
 }
 
 func (ast *Assertion) buildIncrementalRoleLinks(rm rbac.RoleManager, op PolicyOp, rules [][]string) error {
@@ -151,17 +165,80 @@ func (ast *Assertion) addConditionalRoleLink(rule []string, domainRule []string)
 	var err error
 	if len(domainRule) == 0 {
 		err = ast.CondRM.AddLink(rule[0], rule[1])
-		if err == nil {
-			ast.CondRM.SetLinkConditionFuncParams(rule[0], rule[1], rule[len(ast.Tokens):]...)
+	} else {
+		err = ast.CondRM.AddLink(rule[0], rule[1], domainRule[0])
+	}
+	if err == nil {
+		ast.setLinkCondition(rule, domainRule)
+	}
+	return err
+}
+
+// setLinkCondition binds the named link condition function referenced by the rule, if any,
+// and sets the parameters for LinkConditionFunc.
+func (ast *Assertion) setLinkCondition(rule []string, domainRule []string) {
+	fn, params := ast.resolveLinkConditionFunc(rule[len(ast.Tokens):])
+	if len(domainRule) == 0 {
+		if fn != nil {
+			ast.CondRM.AddLinkConditionFunc(rule[0], rule[1], fn)
 		}
+		ast.CondRM.SetLinkConditionFuncParams(rule[0], rule[1], params...)
 	} else {
 		domain := domainRule[0]
-		err = ast.CondRM.AddLink(rule[0], rule[1], domain)
-		if err == nil {
-			ast.CondRM.SetDomainLinkConditionFuncParams(rule[0], rule[1], domain, rule[len(ast.Tokens):]...)
+		if fn != nil {
+			ast.CondRM.AddDomainLinkConditionFunc(rule[0], rule[1], domain, fn)
 		}
+		ast.CondRM.SetDomainLinkConditionFuncParams(rule[0], rule[1], domain, params...)
 	}
-	return err
+}
+
+// resolveLinkConditionFunc looks up the function named by the first condition column.
+// If it is registered, the function and the remaining columns are returned,
+// otherwise all columns are returned as parameters.
+func (ast *Assertion) resolveLinkConditionFunc(params []string) (rbac.LinkConditionFunc, []string) {
+	if len(params) == 0 || ast.LinkConditionFuncs == nil {
+		return nil, params
+	}
+	if fn, ok := ast.LinkConditionFuncs[strings.TrimSpace(params[0])]; ok {
+		return fn, params[1:]
+	}
+	return nil, params
+}
+
+// addLinkConditionFunc registers fn under name and binds it to the already loaded rules referencing it.
+func (ast *Assertion) addLinkConditionFunc(name string, fn rbac.LinkConditionFunc) {
+	if ast.LinkConditionFuncs == nil {
+		ast.LinkConditionFuncs = make(map[string]rbac.LinkConditionFunc)
+	}
+	ast.LinkConditionFuncs[name] = fn
+
+	if ast.CondRM == nil {
+		return
+	}
+	for _, rule := range ast.Policy {
+		if len(rule) <= len(ast.Tokens) || strings.TrimSpace(rule[len(ast.Tokens)]) != name {
+			continue
+		}
+		ast.setLinkCondition(rule, rule[2:len(ast.Tokens)])
+	}
+}
+
+// fillDefaults returns rule completed with the default values of its missing optional trailing fields,
+// or rule itself if it has no missing field or a missing field has no default value.
+func (ast *Assertion) fillDefaults(rule []string) []string {
+	if len(rule) >= len(ast.Tokens) || len(ast.FieldDefaults) == 0 {
+		return rule
+	}
+	filled := make([]string, len(ast.Tokens))
+	copy(filled, rule)
+	for i := len(rule); i < len(ast.Tokens); i++ {
+		value, ok := ast.FieldDefaults[i]
+		if !ok {
+			return rule
+		}
+		filled[i] = value
+	}
+	return filled
 }
 
 func (ast *Assertion) copy() *Assertion {
@@ -183,6 +260,22 @@ func (ast *Assertion) copy() *Assertion {
 	}
 	ast.FieldIndexMutex.RUnlock()
 
+	var linkConditionFuncs map[string]rbac.LinkConditionFunc
+	if ast.LinkConditionFuncs != nil {
+		linkConditionFuncs = make(map[string]rbac.LinkConditionFunc, len(ast.LinkConditionFuncs))
+		for k, v := range ast.LinkConditionFuncs {
+			linkConditionFuncs[k] = v
+		}
+	}
+
+	var fieldDefaults map[int]string
+	if ast.FieldDefaults != nil {
+		fieldDefaults = make(map[int]string, len(ast.FieldDefaults))
+		for k, v := range ast.FieldDefaults {
+			fieldDefaults[k] = v
+		}
+	}
+
 	newAst := &Assertion{
 		Key:           ast.Key,
 		Value:         ast.Value,
@@ -193,6 +286,11 @@ func (ast *Assertion) copy() *Assertion {
 		ParamsTokens:  append([]string(nil), ast.ParamsTokens...),
 		RM:            ast.RM,
 		CondRM:        ast.CondRM,
+
+		LinkConditionFuncs: linkConditionFuncs,
+		FieldDefaults:      fieldDefaults,
+		PolicySchema:       ast.PolicySchema,
+		policySchemaErr:    ast.policySchemaErr,
 	}
 
 	return newAst
@@ -0,0 +1,313 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/casbin/casbin/v3/constant"
+	"github.com/casbin/govaluate"
+)
+
+// CombiningAlgorithm is the way the effects of the matched rules are combined, written in the policy_effect section.
+type CombiningAlgorithm string
+
+const (
+	// AllowOverride allows a request if a matched rule allows it.
+	AllowOverride CombiningAlgorithm = constant.AllowOverrideEffect
+	// DenyOverride allows a request unless a matched rule denies it.
+	DenyOverride CombiningAlgorithm = constant.DenyOverrideEffect
+	// AllowAndDeny allows a request if a matched rule allows it and no matched rule denies it.
+	AllowAndDeny CombiningAlgorithm = constant.AllowAndDenyEffect
+	// Priority takes the effect of the matched rule with the highest priority.
+	Priority CombiningAlgorithm = constant.PriorityEffect
+	// SubjectPriority takes the effect of the matched rule whose subject is the lowest in the role hierarchy.
+	SubjectPriority CombiningAlgorithm = constant.SubjectPriorityEffect
+)
+
+// matcher precedences, an operand is put in parentheses when needed by the operator using it.
+const (
+	precedenceCall = iota
+	precedenceComparison
+	precedenceAnd
+	precedenceOr
+	precedenceRaw
+)
+
+// Matcher is an expression of the matchers section built with the matcher helpers, e.g.
+// And(HasRole("g", "r.sub", "p.sub"), KeyMatch("r.obj", "p.obj"), Equals("r.act", "p.act")).
+type Matcher struct {
+	expr       string
+	precedence int
+}
+
+// String returns the expression of the matcher.
+func (m Matcher) String() string {
+	return m.expr
+}
+
+// Raw returns a matcher of an expression written by hand, e.g. Raw("r.sub.Age > 18").
+func Raw(expr string) Matcher {
+	return Matcher{expr: expr, precedence: precedenceRaw}
+}
+
+// Equals returns a matcher of the equality of two operands, e.g. Equals("r.act", "p.act").
+func Equals(left string, right string) Matcher {
+	return Matcher{expr: left + " == " + right, precedence: precedenceComparison}
+}
+
+// Call returns a matcher calling a function, e.g. Call("keyMatch2", "r.obj", "p.obj").
+// A custom function should be declared with Builder.DeclareFunction.
+func Call(function string, args ...string) Matcher {
+	return Matcher{expr: function + "(" + strings.Join(args, ", ") + ")", precedence: precedenceCall}
+}
+
+// HasRole returns a matcher checking a role of the role definition roleType, e.g. HasRole("g", "r.sub", "p.sub"),
+// or HasRole("g", "r.sub", "p.sub", "r.dom") with a domain.
+func HasRole(roleType string, args ...string) Matcher {
+	return Call(roleType, args...)
+}
+
+// KeyMatch returns a matcher calling keyMatch, see util.KeyMatch.
+func KeyMatch(key1 string, key2 string) Matcher {
+	return Call("keyMatch", key1, key2)
+}
+
+// KeyMatch2 returns a matcher calling keyMatch2, see util.KeyMatch2.
+func KeyMatch2(key1 string, key2 string) Matcher {
+	return Call("keyMatch2", key1, key2)
+}
+
+// RegexMatch returns a matcher calling regexMatch, see util.RegexMatch.
+func RegexMatch(key1 string, key2 string) Matcher {
+	return Call("regexMatch", key1, key2)
+}
+
+// IPMatch returns a matcher calling ipMatch, see util.IPMatch.
+func IPMatch(ip1 string, ip2 string) Matcher {
+	return Call("ipMatch", ip1, ip2)
+}
+
+// GlobMatch returns a matcher calling globMatch, see util.GlobMatch.
+func GlobMatch(key1 string, key2 string) Matcher {
+	return Call("globMatch", key1, key2)
+}
+
+// And returns a matcher of the conjunction of matchers.
+func And(matchers ...Matcher) Matcher {
+	return join(matchers, " && ", precedenceAnd)
+}
+
+// Or returns a matcher of the disjunction of matchers.
+func Or(matchers ...Matcher) Matcher {
+	return join(matchers, " || ", precedenceOr)
+}
+
+// Not returns a matcher of the negation of matcher.
+func Not(matcher Matcher) Matcher {
+	if matcher.precedence == precedenceCall {
+		return Matcher{expr: "!" + matcher.expr, precedence: precedenceCall}
+	}
+	return Matcher{expr: "!(" + matcher.expr + ")", precedence: precedenceCall}
+}
+
+func join(matchers []Matcher, operator string, precedence int) Matcher {
+	if len(matchers) == 1 {
+		return matchers[0]
+	}
+	exprs := make([]string, len(matchers))
+	for i, matcher := range matchers {
+		if matcher.precedence > precedence || matcher.precedence == precedenceRaw {
+			exprs[i] = "(" + matcher.expr + ")"
+		} else {
+			exprs[i] = matcher.expr
+		}
+	}
+	return Matcher{expr: strings.Join(exprs, operator), precedence: precedence}
+}
+
+type definition struct {
+	key   string
+	value string
+}
+
+// Builder builds a validated model in Go, e.g.
+//
+//	m, err := model.NewBuilder().
+//		AddRequestDefinition("r", "sub", "obj", "act").
+//		AddPolicyDefinition("p", "sub", "obj", "act").
+//		AddRoleDefinition("g", 2).
+//		SetEffect(model.AllowOverride).
+//		SetMatcher(model.And(model.HasRole("g", "r.sub", "p.sub"), model.Equals("r.obj", "p.obj"), model.Equals("r.act", "p.act"))).
+//		Build()
+//
+// The errors of the definitions are returned by Build.
+type Builder struct {
+	definitions map[string][]definition
+	functions   []string
+	err         error
+}
+
+var (
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	referenceRegex  = regexp.MustCompile(`\b([rp][0-9]*)\.([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// NewBuilder creates an empty model builder.
+func NewBuilder() *Builder {
+	return &Builder{definitions: make(map[string][]definition)}
+}
+
+func (b *Builder) fail(format string, a ...interface{}) *Builder {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, a...)
+	}
+	return b
+}
+
+func (b *Builder) add(sec string, key string, value string) *Builder {
+	if len(b.definitions[sec]) == 0 && key != sec || len(b.definitions[sec]) > 0 && key != sec+getKeySuffix(len(b.definitions[sec])+1) {
+		return b.fail("invalid key %s of %s, the keys should be %s, %s2, %s3...", key, sectionNameMap[sec], sec, sec, sec)
+	}
+	b.definitions[sec] = append(b.definitions[sec], definition{key: key, value: value})
+	return b
+}
+
+func (b *Builder) addTokens(sec string, key string, tokens []string) *Builder {
+	if len(tokens) == 0 {
+		return b.fail("%s has no token", key)
+	}
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		name := token
+		if sec == "p" {
+			// an optional field with its default value, e.g. "eft=allow"
+			name = strings.SplitN(token, "=", 2)[0]
+		}
+		if !identifierRegex.MatchString(name) {
+			return b.fail("invalid token %q of %s", token, key)
+		}
+		if seen[name] {
+			return b.fail("duplicate token %s of %s", name, key)
+		}
+		seen[name] = true
+	}
+	return b.add(sec, key, strings.Join(tokens, ", "))
+}
+
+// AddRequestDefinition adds a request definition, e.g. AddRequestDefinition("r", "sub", "obj", "act").
+func (b *Builder) AddRequestDefinition(key string, tokens ...string) *Builder {
+	return b.addTokens("r", key, tokens)
+}
+
+// AddPolicyDefinition adds a policy definition, e.g. AddPolicyDefinition("p", "sub", "obj", "act", "eft=allow"),
+// a token can declare the default value of an optional trailing field.
+func (b *Builder) AddPolicyDefinition(key string, tokens ...string) *Builder {
+	return b.addTokens("p", key, tokens)
+}
+
+// AddRoleDefinition adds a role definition of arity fields, e.g. AddRoleDefinition("g", 2) for "g = _, _",
+// or AddRoleDefinition("g", 3) for roles in domains.
+func (b *Builder) AddRoleDefinition(key string, arity int) *Builder {
+	if arity < 2 {
+		return b.fail("the arity of %s should be at least 2", key)
+	}
+	return b.add("g", key, strings.TrimSuffix(strings.Repeat("_, ", arity), ", "))
+}
+
+// SetEffect sets the policy effect.
+func (b *Builder) SetEffect(effect CombiningAlgorithm) *Builder {
+	b.definitions["e"] = []definition{{key: "e", value: string(effect)}}
+	return b
+}
+
+// SetMatcher sets the matcher.
+func (b *Builder) SetMatcher(matcher Matcher) *Builder {
+	b.definitions["m"] = []definition{{key: "m", value: matcher.expr}}
+	return b
+}
+
+// DeclareFunction declares custom functions used by the matcher, which are added to the enforcer later.
+func (b *Builder) DeclareFunction(names ...string) *Builder {
+	b.functions = append(b.functions, names...)
+	return b
+}
+
+// Build returns the model, or the first error of its definitions.
+// The matcher is checked to reference only defined tokens and known functions, and to compile.
+func (b *Builder) Build() (Model, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	for _, sec := range requiredSections {
+		if len(b.definitions[sec]) == 0 {
+			return nil, fmt.Errorf("missing required section %s", sectionNameMap[sec])
+		}
+	}
+
+	m := NewModel()
+	for _, sec := range textSections {
+		for _, def := range b.definitions[sec] {
+			m.AddDef(sec, def.key, def.value)
+		}
+	}
+	if err := m.validateFieldDefaults(); err != nil {
+		return nil, err
+	}
+	if err := b.validateMatcher(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (b *Builder) validateMatcher(m Model) error {
+	matcher := b.definitions["m"][0].value
+	for _, reference := range referenceRegex.FindAllStringSubmatch(matcher, -1) {
+		sec := reference[1][:1]
+		ast, ok := m[sec][reference[1]]
+		if !ok {
+			return fmt.Errorf("the matcher references %s.%s of an undefined %s", reference[1], reference[2], sectionNameMap[sec])
+		}
+		if sec == "p" && reference[2] == "eft" {
+			continue
+		}
+		found := false
+		for _, token := range ast.Tokens {
+			if token == reference[1]+"_"+reference[2] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("the matcher references the undefined token %s of %s", reference[2], reference[1])
+		}
+	}
+
+	fm := LoadFunctionMap()
+	functions := fm.GetFunctions()
+	stub := func(args ...interface{}) (interface{}, error) { return true, nil }
+	for key := range m["g"] {
+		functions[key] = stub
+	}
+	for _, name := range append(b.functions, "eval") {
+		functions[name] = stub
+	}
+	if _, err := govaluate.NewEvaluableExpressionWithFunctions(m["m"]["m"].Value, functions); err != nil {
+		return fmt.Errorf("invalid matcher %s: %w", matcher, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	m, err := NewBuilder().
+		AddRequestDefinition("r", "sub", "obj", "act").
+		AddPolicyDefinition("p", "sub", "obj", "act").
+		AddRoleDefinition("g", 2).
+		SetEffect(AllowOverride).
+		SetMatcher(And(HasRole("g", "r.sub", "p.sub"), Equals("r.obj", "p.obj"), Equals("r.act", "p.act"))).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	expected, _ := NewModelFromFile(filepath.Join("..", "examples", "rbac_model.conf"))
+	testModelEquals(t, "builder", m, expected)
+
+	text := `[request_definition]
+r = sub, obj, act
+[policy_definition]
+p = sub, obj, act
+[role_definition]
+g = _, _
+[policy_effect]
+e = some(where (p.eft == allow))
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`
+	if m.ToText() != text {
+		t.Errorf("ToText() = %s, supposed to be %s", m.ToText(), text)
+	}
+}
+
+func TestBuilderMatchers(t *testing.T) {
+	for _, tc := range []struct {
+		matcher  Matcher
+		expected string
+	}{
+		{Or(And(Equals("a", "b"), KeyMatch2("c", "d")), Raw("e > 1")), "a == b && keyMatch2(c, d) || (e > 1)"},
+		{And(Or(Equals("a", "b"), RegexMatch("c", "d")), IPMatch("e", "f")), "(a == b || regexMatch(c, d)) && ipMatch(e, f)"},
+		{Not(And(GlobMatch("a", "b"), KeyMatch("c", "d"))), "!(globMatch(a, b) && keyMatch(c, d))"},
+		{Not(Call("f", "a")), "!f(a)"},
+		{And(Equals("a", "b")), "a == b"},
+	} {
+		if tc.matcher.String() != tc.expected {
+			t.Errorf("matcher = %s, supposed to be %s", tc.matcher, tc.expected)
+		}
+	}
+}
+
+func TestBuilderMultipleTypes(t *testing.T) {
+	m, err := NewBuilder().
+		AddRequestDefinition("r", "sub", "obj", "act").
+		AddPolicyDefinition("p", "sub", "obj", "act", "eft=allow").
+		AddPolicyDefinition("p2", "sub", "act").
+		AddRoleDefinition("g", 3).
+		AddRoleDefinition("g2", 2).
+		SetEffect(Priority).
+		DeclareFunction("isOwner").
+		SetMatcher(Or(And(HasRole("g", "r.sub", "p.sub", "r.obj"), Equals("r.act", "p.act")), Call("isOwner", "r.sub", "r.obj"))).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	fromText, err := NewModelFromString(m.ToText())
+	if err != nil {
+		t.Fatalf("NewModelFromString() error: %v", err)
+	}
+	testModelEquals(t, "builder text", fromText, m)
+	if m["p"]["p"].FieldDefaults[3] != "allow" {
+		t.Errorf("the optional field eft should default to allow")
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	valid := func() *Builder {
+		return NewBuilder().
+			AddRequestDefinition("r", "sub", "obj", "act").
+			AddPolicyDefinition("p", "sub", "obj", "act").
+			SetEffect(AllowOverride)
+	}
+	matcher := Equals("r.sub", "p.sub")
+	for _, tc := range []struct {
+		builder *Builder
+		message string
+	}{
+		{valid(), "missing required section matchers"},
+		{valid().SetMatcher(Equals("r.sub", "p.owner")), "undefined token owner of p"},
+		{valid().SetMatcher(Equals("r2.sub", "p.sub")), "r2.sub of an undefined request_definition"},
+		{valid().SetMatcher(HasRole("g", "r.sub", "p.sub")), "Undefined function g"},
+		{valid().SetMatcher(Call("isOwner", "r.sub")), "Undefined function isOwner"},
+		{valid().SetMatcher(Raw("r.sub == == p.sub")), "invalid matcher"},
+		{valid().SetMatcher(matcher).AddPolicyDefinition("p3", "sub"), "invalid key p3"},
+		{valid().SetMatcher(matcher).AddRequestDefinition("r2", "sub", "sub"), "duplicate token sub of r2"},
+		{valid().SetMatcher(matcher).AddPolicyDefinition("p2", "sub-id"), "invalid token"},
+		{valid().SetMatcher(matcher).AddPolicyDefinition("p2", "sub=alice", "obj"), "required field obj of p2 follows an optional field"},
+		{valid().SetMatcher(matcher).AddRoleDefinition("g", 1), "arity of g"},
+	} {
+		if _, err := tc.builder.Build(); err == nil || !strings.Contains(err.Error(), tc.message) {
+			t.Errorf("Build() error = %v, supposed to contain %s", err, tc.message)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package model
 
 import (
+	"context"
 	"sync"
 
 	"github.com/casbin/casbin/v3/util"
@@ -23,20 +24,30 @@ import (
 
 // FunctionMap represents the collection of Function.
 type FunctionMap struct {
-	fns *sync.Map
+	fns    *sync.Map
+	ctxFns *sync.Map
 }
 
 // [string]govaluate.ExpressionFunction
 
+// ContextFunction is an expression function that receives the context of the enforcement.
+type ContextFunction func(ctx context.Context, args ...interface{}) (interface{}, error)
+
 // AddFunction adds an expression function.
 func (fm *FunctionMap) AddFunction(name string, function govaluate.ExpressionFunction) {
 	fm.fns.LoadOrStore(name, function)
 }
 
+// AddContextFunction adds an expression function that receives the context of the enforcement.
+func (fm *FunctionMap) AddContextFunction(name string, function ContextFunction) {
+	fm.ctxFns.LoadOrStore(name, function)
+}
+
 // LoadFunctionMap loads an initial function map.
 func LoadFunctionMap() FunctionMap {
 	fm := &FunctionMap{}
 	fm.fns = &sync.Map{}
+	fm.ctxFns = &sync.Map{}
 
 	fm.AddFunction("keyMatch", util.KeyMatchFunc)
 	fm.AddFunction("keyGet", util.KeyGetFunc)
@@ -64,3 +75,18 @@ func (fm *FunctionMap) GetFunctions() map[string]govaluate.ExpressionFunction {
 
 	return ret
 }
+
+// GetContextFunctions returns a map with all the context-aware functions.
+func (fm *FunctionMap) GetContextFunctions() map[string]ContextFunction {
+	ret := make(map[string]ContextFunction)
+	if fm.ctxFns == nil {
+		return ret
+	}
+
+	fm.ctxFns.Range(func(k interface{}, v interface{}) bool {
+		ret[k.(string)] = v.(ContextFunction)
+		return true
+	})
+
+	return ret
+}
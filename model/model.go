@@ -44,6 +44,7 @@ var sectionNameMap = map[string]string{
 	"e": "policy_effect",
 	"m": "matchers",
 	"c": "constraint_definition",
+	"s": "policy_schema",
 }
 
 // Minimal required sections for a model to be valid.
@@ -81,7 +82,18 @@ func (model Model) AddDef(sec string, key string, value string) bool {
 	if sec == "r" || sec == "p" {
 		ast.Tokens = strings.Split(ast.Value, ",")
 		for i := range ast.Tokens {
-			ast.Tokens[i] = key + "_" + strings.TrimSpace(ast.Tokens[i])
+			token := ast.Tokens[i]
+			if sec == "p" {
+				// an optional field declares its default value, e.g. "eft=allow"
+				if j := strings.Index(token, "="); j != -1 {
+					if ast.FieldDefaults == nil {
+						ast.FieldDefaults = make(map[int]string)
+					}
+					ast.FieldDefaults[i] = strings.TrimSpace(token[j+1:])
+					token = token[:j]
+				}
+			}
+			ast.Tokens[i] = key + "_" + strings.TrimSpace(token)
 		}
 	} else if sec == "g" {
 		ast.ParamsTokens = getParamsToken(ast.Value)
@@ -106,6 +118,12 @@ func (model Model) AddDef(sec string, key string, value string) bool {
 	}
 
 	model[sec][key] = &ast
+	switch sec {
+	case "p":
+		model.compilePolicySchema(key)
+	case "s":
+		model.compilePolicySchema("p" + strings.TrimPrefix(key, "s"))
+	}
 	return true
 }
 
@@ -198,6 +216,14 @@ func (model Model) loadModelFromConfig(cfg config.ConfigInterface) error {
 		return fmt.Errorf("missing required sections: %s", strings.Join(ms, ","))
 	}
 
+	if err := model.validateFieldDefaults(); err != nil {
+		return err
+	}
+
+	if err := model.validatePolicySchemaDefinitions(); err != nil {
+		return err
+	}
+
 	// Validate constraints after model is loaded
 	if err := model.ValidateConstraints(); err != nil {
 		return err
@@ -206,6 +232,57 @@ func (model Model) loadModelFromConfig(cfg config.ConfigInterface) error {
 	return nil
 }
 
+// validateFieldDefaults returns an error if an optional field of a policy definition is followed by a required field.
+func (model Model) validateFieldDefaults() error {
+	for ptype, ast := range model["p"] {
+		for i := range ast.Tokens {
+			if _, ok := ast.FieldDefaults[i]; !ok && i > 0 {
+				if _, ok := ast.FieldDefaults[i-1]; ok {
+					return fmt.Errorf("the required field %s of %s follows an optional field", strings.TrimPrefix(ast.Tokens[i], ptype+"_"), ptype)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FillPolicyDefaults returns rule completed with the default values of the optional trailing fields declared
+// in the policy definition of ptype, e.g. "p = sub, obj, act, eft=allow" completes the rule ["alice", "data1", "read"]
+// to ["alice", "data1", "read", "allow"]. Other rules are returned as is.
+func (model Model) FillPolicyDefaults(sec string, ptype string, rule []string) []string {
+	if sec != "p" || model[sec] == nil || model[sec][ptype] == nil {
+		return rule
+	}
+	return model[sec][ptype].fillDefaults(rule)
+}
+
+// FillPoliciesDefaults returns rules completed with the default values of the optional trailing fields, see FillPolicyDefaults.
+func (model Model) FillPoliciesDefaults(sec string, ptype string, rules [][]string) [][]string {
+	if sec != "p" || model[sec] == nil || model[sec][ptype] == nil || len(model[sec][ptype].FieldDefaults) == 0 {
+		return rules
+	}
+	filled := make([][]string, len(rules))
+	for i, rule := range rules {
+		filled[i] = model[sec][ptype].fillDefaults(rule)
+	}
+	return filled
+}
+
+// CheckRequiredFields returns an error if a rule of a policy type with optional fields misses a required field.
+// It should be called on rules completed by FillPoliciesDefaults.
+func (model Model) CheckRequiredFields(sec string, ptype string, rules [][]string) error {
+	if sec != "p" || model[sec] == nil || model[sec][ptype] == nil || len(model[sec][ptype].FieldDefaults) == 0 {
+		return nil
+	}
+	tokens := model[sec][ptype].Tokens
+	for _, rule := range rules {
+		if len(rule) < len(tokens) {
+			return fmt.Errorf("the required field %s of %s is missing in rule: %v", tokens[len(rule)], ptype, rule)
+		}
+	}
+	return nil
+}
+
 func (model Model) hasSection(sec string) bool {
 	section := model[sec]
 	return section != nil
@@ -343,52 +420,62 @@ func (model Model) SortPoliciesByPriority() error {
 	return nil
 }
 
-var (
-	pPattern = regexp.MustCompile("^p_")
-	rPattern = regexp.MustCompile("^r_")
-)
+// textSections are the sections of the model text, in the order they are written.
+var textSections = []string{"r", "p", "g", "c", "s", "e", "m"}
 
-func (model Model) ToText() string {
+// sectionValues returns the values of the assertions by section and key as written in the model text,
+// e.g. with "r.sub" for the token "r_sub".
+func (model Model) sectionValues() map[string]map[string]string {
 	tokenPatterns := make(map[string]string)
-
-	for _, ptype := range []string{"r", "p"} {
-		for _, token := range model[ptype][ptype].Tokens {
-			tokenPatterns[token] = rPattern.ReplaceAllString(pPattern.ReplaceAllString(token, "p."), "r.")
+	for _, sec := range []string{"r", "p"} {
+		for key, ast := range model[sec] {
+			for _, token := range ast.Tokens {
+				tokenPatterns[token] = key + "." + strings.TrimPrefix(token, key+"_")
+			}
+			if sec == "p" {
+				tokenPatterns[key+"_eft"] = key + ".eft"
+			}
 		}
 	}
-	if strings.Contains(model["e"]["e"].Value, "p_eft") {
-		tokenPatterns["p_eft"] = "p.eft"
-	}
-	s := strings.Builder{}
-	writeString := func(sec string) {
-		for ptype := range model[sec] {
-			value := model[sec][ptype].Value
-			for tokenPattern, newToken := range tokenPatterns {
-				value = strings.Replace(value, tokenPattern, newToken, -1)
+
+	sections := make(map[string]map[string]string)
+	for _, sec := range textSections {
+		if _, ok := model[sec]; !ok {
+			continue
+		}
+		sections[sec] = make(map[string]string)
+		for key, ast := range model[sec] {
+			value := ast.Value
+			if sec == "r" || sec == "p" || sec == "e" || sec == "m" {
+				for tokenPattern, newToken := range tokenPatterns {
+					value = strings.Replace(value, tokenPattern, newToken, -1)
+				}
 			}
-			s.WriteString(fmt.Sprintf("%s = %s\n", sec, value))
+			sections[sec][key] = value
 		}
 	}
-	s.WriteString("[request_definition]\n")
-	writeString("r")
-	s.WriteString("[policy_definition]\n")
-	writeString("p")
-	if _, ok := model["g"]; ok {
-		s.WriteString("[role_definition]\n")
-		for ptype := range model["g"] {
-			s.WriteString(fmt.Sprintf("%s = %s\n", ptype, model["g"][ptype].Value))
+	return sections
+}
+
+// ToText returns the model as text in the INI dialect of the config package, see LoadModelFromText.
+func (model Model) ToText() string {
+	s := strings.Builder{}
+	sections := model.sectionValues()
+	for _, sec := range textSections {
+		values, ok := sections[sec]
+		if !ok {
+			continue
 		}
-	}
-	if _, ok := model["c"]; ok {
-		s.WriteString("[constraint_definition]\n")
-		for ptype := range model["c"] {
-			s.WriteString(fmt.Sprintf("%s = %s\n", ptype, model["c"][ptype].Value))
+		s.WriteString("[" + sectionNameMap[sec] + "]\n")
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s.WriteString(fmt.Sprintf("%s = %s\n", key, values[key]))
 		}
 	}
-	s.WriteString("[policy_effect]\n")
-	writeString("e")
-	s.WriteString("[matchers]\n")
-	writeString("m")
 	return s.String()
 }
 
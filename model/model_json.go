@@ -0,0 +1,121 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v3/config"
+)
+
+// NewModelFromJSON creates a model from JSON, see LoadModelFromJSON.
+func NewModelFromJSON(data []byte) (Model, error) {
+	m := NewModel()
+
+	err := m.LoadModelFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// NewModelFromJSONFile creates a model from a .JSON file, see LoadModelFromJSON.
+func NewModelFromJSONFile(path string) (Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewModelFromJSON(data)
+}
+
+// LoadModelFromJSON loads the model from JSON holding an object for each section of the model text,
+// which maps the keys of the section to their values, e.g.
+//
+//	{
+//	  "request_definition": {"r": "sub, obj, act"},
+//	  "policy_definition": {"p": "sub, obj, act", "p2": "sub, act"},
+//	  "role_definition": {"g": "_, _"},
+//	  "policy_effect": {"e": "some(where (p.eft == allow))"},
+//	  "matchers": {"m": "g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act"}
+//	}
+func (model Model) LoadModelFromJSON(data []byte) error {
+	var sections map[string]map[string]string
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("invalid JSON model: %w", err)
+	}
+
+	cfg, err := config.NewConfigFromText("")
+	if err != nil {
+		return err
+	}
+	for name, values := range sections {
+		sec := ""
+		for s, sectionName := range sectionNameMap {
+			if sectionName == name {
+				sec = s
+			}
+		}
+		if sec == "" {
+			return fmt.Errorf("invalid JSON model: unknown section %s", name)
+		}
+
+		for key, value := range values {
+			if err := checkSectionKey(sec, key, values); err != nil {
+				return fmt.Errorf("invalid JSON model: %s in section %s", err.Error(), name)
+			}
+			if err := cfg.Set(name+"::"+key, strings.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return model.loadModelFromConfig(cfg)
+}
+
+// checkSectionKey returns an error if key is not a key of sec, e.g. "p" or "p2" for "p",
+// or if a key before it is missing from values, since the keys of a section are loaded in sequence.
+func checkSectionKey(sec string, key string, values map[string]string) error {
+	if !strings.HasPrefix(key, sec) {
+		return fmt.Errorf("invalid key %s", key)
+	}
+	if key == sec {
+		return nil
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(key, sec))
+	if err != nil || i < 2 || key != sec+getKeySuffix(i) {
+		return fmt.Errorf("invalid key %s", key)
+	}
+	for j := 1; j < i; j++ {
+		if _, ok := values[sec+getKeySuffix(j)]; !ok {
+			return fmt.Errorf("missing key %s before %s", sec+getKeySuffix(j), key)
+		}
+	}
+	return nil
+}
+
+// ToJSON returns the model as JSON, with the same sections and values as ToText, see LoadModelFromJSON.
+func (model Model) ToJSON() ([]byte, error) {
+	sections := make(map[string]map[string]string)
+	for sec, values := range model.sectionValues() {
+		sections[sectionNameMap[sec]] = values
+	}
+	return json.MarshalIndent(sections, "", "  ")
+}
@@ -159,3 +159,95 @@ func testModelToText(t *testing.T, mData, mExpected string) {
 		}
 	}
 }
+
+func testModelEquals(t *testing.T, name string, m Model, expected Model) {
+	t.Helper()
+	for sec, assertions := range expected {
+		for key, ast := range assertions {
+			other, ok := m[sec][key]
+			if !ok {
+				t.Errorf("%s: missing assertion %s", name, key)
+				continue
+			}
+			if other.Value != ast.Value || strings.Join(other.Tokens, ",") != strings.Join(ast.Tokens, ",") {
+				t.Errorf("%s: assertion %s = %s %v, supposed to be %s %v", name, key, other.Value, other.Tokens, ast.Value, ast.Tokens)
+			}
+		}
+		if len(m[sec]) != len(assertions) {
+			t.Errorf("%s: section %s has %d assertions, supposed to have %d", name, sec, len(m[sec]), len(assertions))
+		}
+	}
+}
+
+func TestModelJSON(t *testing.T) {
+	for _, name := range []string{
+		"rbac_model.conf",
+		"rbac_with_domains_model.conf",
+		"rbac_with_multiple_policy_model.conf",
+		"rbac_with_different_types_of_roles_model.conf",
+		"rbac_with_constraints_model.conf",
+		"rbac_with_schema_model.conf",
+		"rbac_with_optional_fields_model.conf",
+		"priority_model.conf",
+	} {
+		m, err := NewModelFromFile(filepath.Join("..", "examples", name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		data, err := m.ToJSON()
+		if err != nil {
+			t.Fatalf("%s: ToJSON() error: %v", name, err)
+		}
+		fromJSON, err := NewModelFromJSON(data)
+		if err != nil {
+			t.Fatalf("%s: NewModelFromJSON() error: %v\n%s", name, err, data)
+		}
+		testModelEquals(t, name+" from JSON", fromJSON, m)
+
+		// The text of a model holds the same assertions, including p2 and g2.
+		fromText, err := NewModelFromString(m.ToText())
+		if err != nil {
+			t.Fatalf("%s: NewModelFromString() error: %v", name, err)
+		}
+		testModelEquals(t, name+" from text", fromText, m)
+	}
+}
+
+func TestModelJSONSections(t *testing.T) {
+	fromFile, err := NewModelFromJSONFile(filepath.Join("..", "examples", "rbac_model.json"))
+	if err != nil {
+		t.Fatalf("NewModelFromJSONFile() error: %v", err)
+	}
+	expected, _ := NewModelFromFile(filepath.Join("..", "examples", "rbac_model.conf"))
+	testModelEquals(t, "rbac_model.json", fromFile, expected)
+
+	m, err := NewModelFromJSON([]byte(`{
+  "request_definition": {"r": "sub, obj, act"},
+  "policy_definition": {"p": "sub, obj, act", "p2": "sub, act"},
+  "role_definition": {"g": "_, _"},
+  "policy_effect": {"e": "some(where (p.eft == allow))"},
+  "matchers": {"m": "g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act"}
+}`))
+	if err != nil {
+		t.Fatalf("NewModelFromJSON() error: %v", err)
+	}
+	if m["p"]["p2"] == nil || strings.Join(m["p"]["p2"].Tokens, ",") != "p2_sub,p2_act" {
+		t.Errorf("p2 should be loaded: %v", m["p"]["p2"])
+	}
+	if m["m"]["m"].Value != "g(r_sub, p_sub) && r_obj == p_obj && r_act == p_act" {
+		t.Errorf("the matcher should be escaped: %s", m["m"]["m"].Value)
+	}
+
+	for data, message := range map[string]string{
+		`{"request_definitions": {"r": "sub"}}`:                "unknown section request_definitions",
+		`{"policy_definition": {"p": "sub", "p3": "sub"}}`:     "missing key p2 before p3",
+		`{"policy_definition": {"p": "sub", "q": "sub"}}`:      "invalid key q",
+		`{"request_definition": {"r": "sub"}}`:                 "missing required sections",
+		`{"request_definition": {"r": ["sub", "obj", "act"]}}`: "invalid JSON model",
+	} {
+		if _, err := NewModelFromJSON([]byte(data)); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("NewModelFromJSON(%s) error = %v, supposed to contain %s", data, err, message)
+		}
+	}
+}
@@ -89,6 +89,18 @@ func (model Model) BuildConditionalRoleLinks(condRmMap map[string]rbac.Condition
 	return nil
 }
 
+// AddLinkConditionFunc registers a link condition function by name for the grouping policy ptype.
+// Rules of ptype whose first condition column is name are bound to fn with the remaining
+// condition columns as parameters, both for the rules already loaded and for those built later.
+func (model Model) AddLinkConditionFunc(ptype string, name string, fn rbac.LinkConditionFunc) error {
+	ast, err := model.GetAssertion("g", ptype)
+	if err != nil {
+		return err
+	}
+	ast.addLinkConditionFunc(name, fn)
+	return nil
+}
+
 // PrintPolicy prints the policy to log.
 func (model Model) PrintPolicy() {
 	// Logger has been removed - this is now a no-op
@@ -147,6 +159,7 @@ func (model Model) HasPolicyEx(sec string, ptype string, rule []string) (bool, e
 	if err != nil {
 		return false, err
 	}
+	rule = model.FillPolicyDefaults(sec, ptype, rule)
 	switch sec {
 	case "p":
 		if len(rule) != len(assertion.Tokens) {
@@ -174,6 +187,7 @@ func (model Model) HasPolicy(sec string, ptype string, rule []string) (bool, err
 	if err != nil {
 		return false, err
 	}
+	rule = model.FillPolicyDefaults(sec, ptype, rule)
 	_, ok := model[sec][ptype].PolicyMap[strings.Join(rule, DefaultSep)]
 	return ok, nil
 }
@@ -199,6 +213,7 @@ func (model Model) AddPolicy(sec string, ptype string, rule []string) error {
 	if err != nil {
 		return err
 	}
+	rule = model.FillPolicyDefaults(sec, ptype, rule)
 	assertion.Policy = append(assertion.Policy, rule)
 	assertion.PolicyMap[strings.Join(rule, DefaultSep)] = len(model[sec][ptype].Policy) - 1
 
@@ -206,7 +221,7 @@ func (model Model) AddPolicy(sec string, ptype string, rule []string) error {
 	if _, ok := assertion.FieldIndexMap[constant.PriorityIndex]; ok {
 		hasPriority = true
 	}
-	if sec == "p" && hasPriority {
+	if sec == "p" && hasPriority && assertion.FieldIndexMap[constant.PriorityIndex] < len(rule) {
 		if idxInsert, err := strconv.Atoi(rule[assertion.FieldIndexMap[constant.PriorityIndex]]); err == nil {
 			i := len(assertion.Policy) - 1
 			for ; i > 0; i-- {
@@ -237,7 +252,7 @@ func (model Model) AddPoliciesWithAffected(sec string, ptype string, rules [][]s
 		return nil, err
 	}
 	var affected [][]string
-	for _, rule := range rules {
+	for _, rule := range model.FillPoliciesDefaults(sec, ptype, rules) {
 		hashKey := strings.Join(rule, DefaultSep)
 		_, ok := model[sec][ptype].PolicyMap[hashKey]
 		if ok {
@@ -259,6 +274,7 @@ func (model Model) RemovePolicy(sec string, ptype string, rule []string) (bool,
 	if err != nil {
 		return false, err
 	}
+	rule = model.FillPolicyDefaults(sec, ptype, rule)
 	key := strings.Join(rule, DefaultSep)
 	index, ok := ast.PolicyMap[key]
 	if !ok {
@@ -282,6 +298,8 @@ func (model Model) UpdatePolicy(sec string, ptype string, oldRule []string, newR
 	if err != nil {
 		return false, err
 	}
+	oldRule = model.FillPolicyDefaults(sec, ptype, oldRule)
+	newRule = model.FillPolicyDefaults(sec, ptype, newRule)
 	oldPolicy := strings.Join(oldRule, DefaultSep)
 	index, ok := model[sec][ptype].PolicyMap[oldPolicy]
 	if !ok {
@@ -301,6 +319,8 @@ func (model Model) UpdatePolicies(sec string, ptype string, oldRules, newRules [
 	if err != nil {
 		return false, err
 	}
+	oldRules = model.FillPoliciesDefaults(sec, ptype, oldRules)
+	newRules = model.FillPoliciesDefaults(sec, ptype, newRules)
 	rollbackFlag := false
 	// index -> []{oldIndex, newIndex}
 	modifiedRuleIndex := make(map[int][]int)
@@ -349,7 +369,7 @@ func (model Model) RemovePoliciesWithAffected(sec string, ptype string, rules []
 		return nil, err
 	}
 	var affected [][]string
-	for _, rule := range rules {
+	for _, rule := range model.FillPoliciesDefaults(sec, ptype, rules) {
 		index, ok := model[sec][ptype].PolicyMap[strings.Join(rule, DefaultSep)]
 		if !ok {
 			continue
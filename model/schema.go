@@ -0,0 +1,247 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v3/errors"
+)
+
+// FieldType is the type of a policy field declared in the policy_schema section.
+type FieldType string
+
+const (
+	// FieldTypeString accepts any value.
+	FieldTypeString FieldType = "string"
+	// FieldTypeEnum accepts one of the listed values, e.g. "act: enum(read|write)".
+	FieldTypeEnum FieldType = "enum"
+	// FieldTypePath accepts a path pattern starting with "/", e.g. "/data/*" or "/data/:id".
+	FieldTypePath FieldType = "path"
+	// FieldTypeCIDR accepts an IP address or a CIDR, e.g. "192.168.2.0/24".
+	FieldTypeCIDR FieldType = "cidr"
+	// FieldTypeRegex accepts a valid regular expression.
+	FieldTypeRegex FieldType = "regex"
+	// FieldTypeInt accepts an integer, e.g. a priority.
+	FieldTypeInt FieldType = "int"
+	// FieldTypeTime accepts a RFC 3339 time, e.g. "2026-01-02T15:04:05Z".
+	FieldTypeTime FieldType = "time"
+)
+
+// FieldSchema is the declared type of a field of a policy definition.
+type FieldSchema struct {
+	Field  string
+	Index  int
+	Type   FieldType
+	Values []string
+}
+
+var fieldSchemaPattern = regexp.MustCompile(`^(\w+)\s*:\s*(\w+)\s*(?:\((.*)\))?$`)
+
+// getSchemaKey returns the key of the schema of ptype, e.g. "s" for "p" and "s2" for "p2".
+func getSchemaKey(ptype string) string {
+	return "s" + strings.TrimPrefix(ptype, "p")
+}
+
+// compilePolicySchema parses the schema of ptype and keeps it on the policy definition of ptype, if both are defined.
+func (model Model) compilePolicySchema(ptype string) {
+	ast, ok := model["p"][ptype]
+	if !ok {
+		return
+	}
+	ast.PolicySchema, ast.policySchemaErr = model.parsePolicySchema(ptype)
+}
+
+// parsePolicySchema parses the schema of ptype, e.g. "sub: string, act: enum(read|write), priority: int".
+// It returns nil if ptype has no schema.
+func (model Model) parsePolicySchema(ptype string) ([]*FieldSchema, error) {
+	schemaAst, ok := model["s"][getSchemaKey(ptype)]
+	if !ok {
+		return nil, nil
+	}
+	ast, ok := model["p"][ptype]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s = %s: missing policy definition %s", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, ptype)
+	}
+
+	var schema []*FieldSchema
+	for _, def := range strings.Split(schemaAst.Value, ",") {
+		matches := fieldSchemaPattern.FindStringSubmatch(strings.TrimSpace(def))
+		if matches == nil {
+			return nil, fmt.Errorf("%w: %s = %s: cannot parse %q", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, strings.TrimSpace(def))
+		}
+
+		index := -1
+		for i, token := range ast.Tokens {
+			if token == ptype+"_"+matches[1] {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("%w: %s = %s: unknown field %s of %s", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, matches[1], ptype)
+		}
+
+		fieldSchema := &FieldSchema{Field: matches[1], Index: index, Type: FieldType(matches[2])}
+		switch fieldSchema.Type {
+		case FieldTypeEnum:
+			for _, value := range strings.Split(matches[3], "|") {
+				if value = strings.TrimSpace(value); value != "" {
+					fieldSchema.Values = append(fieldSchema.Values, value)
+				}
+			}
+			if len(fieldSchema.Values) == 0 {
+				return nil, fmt.Errorf("%w: %s = %s: the enum of field %s has no value", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, matches[1])
+			}
+		case FieldTypeString, FieldTypePath, FieldTypeCIDR, FieldTypeRegex, FieldTypeInt, FieldTypeTime:
+			if matches[3] != "" {
+				return nil, fmt.Errorf("%w: %s = %s: the type %s of field %s takes no parameter", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, matches[2], matches[1])
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s = %s: unknown type %s of field %s", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, matches[2], matches[1])
+		}
+		schema = append(schema, fieldSchema)
+	}
+	return schema, nil
+}
+
+// validatePolicySchemaDefinitions returns an error if a schema of the policy_schema section could not be compiled.
+func (model Model) validatePolicySchemaDefinitions() error {
+	for key, schemaAst := range model["s"] {
+		ptype := "p" + strings.TrimPrefix(key, "s")
+		ast, ok := model["p"][ptype]
+		if !ok {
+			return fmt.Errorf("%w: %s = %s: missing policy definition %s", errors.ErrInvalidPolicySchemaDefinition, schemaAst.Key, schemaAst.Value, ptype)
+		}
+		if ast.policySchemaErr != nil {
+			return ast.policySchemaErr
+		}
+	}
+	return nil
+}
+
+// check returns a message describing why value is not of the type of the field, or "" if it is.
+func (schema *FieldSchema) check(value string) string {
+	switch schema.Type {
+	case FieldTypeEnum:
+		for _, v := range schema.Values {
+			if v == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("not one of %s", strings.Join(schema.Values, ", "))
+	case FieldTypePath:
+		if !strings.HasPrefix(value, "/") || strings.ContainsAny(value, " \t\r\n") {
+			return "not a path pattern"
+		}
+	case FieldTypeCIDR:
+		if net.ParseIP(value) == nil {
+			if _, _, err := net.ParseCIDR(value); err != nil {
+				return "not an IP address or a CIDR"
+			}
+		}
+	case FieldTypeRegex:
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Sprintf("not a regular expression: %s", err.Error())
+		}
+	case FieldTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "not an integer"
+		}
+	case FieldTypeTime:
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "not a RFC 3339 time"
+		}
+	}
+	return ""
+}
+
+// validateRules returns the violations of schema by rules, stopping at the first one if all is false.
+func validateRules(ptype string, schema []*FieldSchema, rules [][]string, all bool) []error {
+	var violations []error
+	for _, rule := range rules {
+		for _, fieldSchema := range schema {
+			if fieldSchema.Index >= len(rule) {
+				continue
+			}
+			if message := fieldSchema.check(rule[fieldSchema.Index]); message != "" {
+				violations = append(violations, errors.NewPolicySchemaViolationError(ptype, fieldSchema.Field, rule[fieldSchema.Index], rule, message))
+				if !all {
+					return violations
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// ValidatePolicySchema returns an error if a rule violates the schema of ptype declared in the policy_schema section,
+// e.g. "s = sub: string, obj: path, act: enum(read|write)" for "p = sub, obj, act".
+// The error names the field and can be matched by errors.Is with errors.ErrPolicySchemaViolation.
+// Rules of a policy type without schema and grouping rules are always valid.
+func (model Model) ValidatePolicySchema(sec string, ptype string, rules [][]string) error {
+	if sec != "p" {
+		return nil
+	}
+	ast, ok := model["p"][ptype]
+	if !ok {
+		_, err := model.parsePolicySchema(ptype)
+		return err
+	}
+	if ast.policySchemaErr != nil || ast.PolicySchema == nil {
+		return ast.policySchemaErr
+	}
+	if violations := validateRules(ptype, ast.PolicySchema, rules, false); len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// ValidatePolicySchemas returns an error if a rule of the current policy violates the schema of its policy type.
+func (model Model) ValidatePolicySchemas() error {
+	for ptype, ast := range model["p"] {
+		if err := model.ValidatePolicySchema("p", ptype, ast.Policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PolicySchemaViolations returns all the violations of the policy schemas by the current policy,
+// e.g. to check the data stored before a schema was declared.
+func (model Model) PolicySchemaViolations() []error {
+	ptypes := make([]string, 0, len(model["p"]))
+	for ptype := range model["p"] {
+		ptypes = append(ptypes, ptype)
+	}
+	sort.Strings(ptypes)
+
+	var violations []error
+	for _, ptype := range ptypes {
+		ast := model["p"][ptype]
+		if ast.policySchemaErr != nil {
+			violations = append(violations, ast.policySchemaErr)
+			continue
+		}
+		violations = append(violations, validateRules(ptype, ast.PolicySchema, ast.Policy, true)...)
+	}
+	return violations
+}
@@ -15,6 +15,7 @@
 package casbin
 
 import (
+	"context"
 	"testing"
 
 	"github.com/casbin/casbin/v3/model"
@@ -769,6 +770,71 @@ func TestTemporalRolesModelWithDomain(t *testing.T) {
 	testDomainEnforce(t, e, "alice", "domain_not_exist", "data8", "write", false)
 }
 
+func TestNamedLinkConditionFuncs(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_domain_named_conditions_model.conf", "examples/rbac_with_domain_named_conditions_policy.csv")
+
+	// Nothing is registered yet, so the links have no condition.
+	testDomainEnforce(t, e, "alice", "domain1", "data1", "read", true)
+	testDomainEnforce(t, e, "alice", "domain2", "data2", "read", true)
+	testDomainEnforce(t, e, "alice", "domain3", "data3", "read", true)
+
+	flagFunc := func(args ...string) (bool, error) {
+		return len(args) != 0 && args[0] == "true", nil
+	}
+
+	if !e.RegisterLinkConditionFunc("timeMatch", util.TimeMatchFunc) {
+		t.Fatal("RegisterLinkConditionFunc() should succeed for a conditional grouping policy")
+	}
+	if !e.RegisterLinkConditionFunc("flag", flagFunc) {
+		t.Fatal("RegisterLinkConditionFunc() should succeed for a conditional grouping policy")
+	}
+	if e.RegisterNamedLinkConditionFunc("g2", "flag", flagFunc) {
+		t.Fatal("RegisterNamedLinkConditionFunc() should fail for a missing grouping policy")
+	}
+
+	testDomainEnforce(t, e, "alice", "domain1", "data1", "read", true)
+	testDomainEnforce(t, e, "alice", "domain2", "data2", "read", false)
+	testDomainEnforce(t, e, "alice", "domain3", "data3", "read", false)
+
+	// Registered functions are bound again when the policy is reloaded.
+	_ = e.LoadPolicy()
+	testDomainEnforce(t, e, "alice", "domain1", "data1", "read", true)
+	testDomainEnforce(t, e, "alice", "domain2", "data2", "read", false)
+	testDomainEnforce(t, e, "alice", "domain3", "data3", "read", false)
+
+	// And when rules are added incrementally.
+	_, _ = e.AddPolicy("data4_admin", "domain4", "data4", "read")
+	_, _ = e.AddGroupingPolicy("bob", "data3_admin", "domain3", "flag", "true", "_")
+	_, _ = e.AddGroupingPolicies([][]string{{"bob", "data4_admin", "domain4", "flag", "false", "_"}})
+	testDomainEnforce(t, e, "bob", "domain3", "data3", "read", true)
+	testDomainEnforce(t, e, "bob", "domain4", "data4", "read", false)
+
+	// And when rules are updated or removed.
+	_, _ = e.UpdateGroupingPolicy([]string{"bob", "data4_admin", "domain4", "flag", "false", "_"}, []string{"bob", "data4_admin", "domain4", "flag", "true", "_"})
+	testDomainEnforce(t, e, "bob", "domain4", "data4", "read", true)
+	_, _ = e.RemoveGroupingPolicies([][]string{{"bob", "data4_admin", "domain4", "flag", "true", "_"}})
+	testDomainEnforce(t, e, "bob", "domain4", "data4", "read", false)
+}
+
+func TestNamedLinkConditionFuncsCtx(t *testing.T) {
+	ce, _ := NewContextEnforcer("examples/rbac_with_domain_named_conditions_model.conf", "examples/rbac_with_domain_named_conditions_policy.csv")
+	e := ce.(*ContextEnforcer)
+	flagFunc := func(args ...string) (bool, error) {
+		return len(args) != 0 && args[0] == "true", nil
+	}
+	if !e.RegisterLinkConditionFunc("flag", flagFunc) {
+		t.Fatal("RegisterLinkConditionFunc() should succeed for a conditional grouping policy")
+	}
+	ctx := context.Background()
+
+	_, _ = e.AddGroupingPolicyCtx(ctx, "bob", "data2_admin", "domain2", "flag", "false", "_")
+	testDomainEnforce(t, e.Enforcer, "bob", "domain2", "data2", "read", false)
+	_, _ = e.UpdateGroupingPolicyCtx(ctx, []string{"bob", "data2_admin", "domain2", "flag", "false", "_"}, []string{"bob", "data2_admin", "domain2", "flag", "true", "_"})
+	testDomainEnforce(t, e.Enforcer, "bob", "domain2", "data2", "read", true)
+	_, _ = e.RemoveGroupingPolicyCtx(ctx, "bob", "data2_admin", "domain2", "flag", "true", "_")
+	testDomainEnforce(t, e.Enforcer, "bob", "domain2", "data2", "read", false)
+}
+
 func TestReBACModel(t *testing.T) {
 	e, _ := NewEnforcer("examples/rebac_model.conf", "examples/rebac_policy.csv")
 
@@ -786,3 +852,87 @@ func TestReBACModel(t *testing.T) {
 	testEnforce(t, e, "bob", "doc3", "read", false)
 	testEnforce(t, e, "bob", "doc3", "write", false)
 }
+
+func TestOptionalPolicyFields(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_optional_fields_model.conf", "examples/rbac_with_optional_fields_policy.csv")
+
+	testEnforce(t, e, "alice", "data1", "read", true)
+	testEnforce(t, e, "alice", "data2", "write", true)
+	testEnforce(t, e, "bob", "data2", "write", false)
+	testEnforce(t, e, "cathy", "data1", "read", false)
+
+	policy, _ := e.GetPolicy()
+	if !util.Array2DEquals(policy, [][]string{
+		{"data1_readers", "data1", "read", "deny", "-1"},
+		{"alice", "data1", "read", "allow", "0"},
+		{"data2_admin", "data2", "read", "allow", "0"},
+		{"data2_admin", "data2", "write", "allow", "0"},
+		{"bob", "data2", "write", "deny", "0"},
+	}) {
+		t.Errorf("the optional fields should be completed: %v", policy)
+	}
+
+	// Rules without their optional fields are completed by the management API.
+	if ok, _ := e.AddPolicy("bob", "data1", "read"); !ok {
+		t.Error("AddPolicy should complete the optional fields")
+	}
+	if ok, _ := e.HasPolicy("bob", "data1", "read", "allow", "0"); !ok {
+		t.Error("the added rule should have the default values")
+	}
+	testEnforce(t, e, "bob", "data1", "read", true)
+	if ok, _ := e.RemovePolicy("bob", "data1", "read"); !ok {
+		t.Error("RemovePolicy should complete the optional fields")
+	}
+	if ok, _ := e.AddPolicy("bob", "data1"); ok {
+		t.Error("a required field should not be completed")
+	}
+
+	if _, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj=data1, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`); err == nil {
+		t.Error("a required field should not follow an optional field")
+	}
+}
+
+func TestModelBuilder(t *testing.T) {
+	m, err := model.NewBuilder().
+		AddRequestDefinition("r", "sub", "obj", "act").
+		AddPolicyDefinition("p", "sub", "obj", "act").
+		AddRoleDefinition("g", 2).
+		SetEffect(model.AllowOverride).
+		SetMatcher(model.And(model.HasRole("g", "r.sub", "p.sub"), model.KeyMatch2("r.obj", "p.obj"), model.Equals("r.act", "p.act"))).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	e, _ := NewEnforcer(m)
+	_, _ = e.AddPolicy("admin", "/data/:id", "read")
+	_, _ = e.AddRoleForUser("alice", "admin")
+	testEnforce(t, e, "alice", "/data/1", "read", true)
+	testEnforce(t, e, "alice", "/data/1", "write", false)
+	testEnforce(t, e, "bob", "/data/1", "read", false)
+}
+
+func TestRBACModelWithInclude(t *testing.T) {
+	e, err := NewEnforcer("examples/rbac_with_include_model.conf", "examples/rbac_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testEnforce(t, e, "alice", "data1", "read", true)
+	testEnforce(t, e, "alice", "data1", "write", false)
+	testEnforce(t, e, "alice", "data2", "read", true)
+	testEnforce(t, e, "alice", "data2", "write", true)
+	testEnforce(t, e, "bob", "data1", "read", false)
+	testEnforce(t, e, "bob", "data2", "write", true)
+}
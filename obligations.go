@@ -0,0 +1,101 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/casbin/casbin/v3/constant"
+	"github.com/casbin/casbin/v3/effector"
+)
+
+// Obligations holds the obligations and advice of the rules that applied to a decision.
+// They are read from the policy fields named "obligation" or "advice", or prefixed with "obligation_" or "advice_", e.g.
+//
+// [policy_definition]
+// p = sub, obj, act, eft, obligation, advice
+//
+// p, alice, data1, read, allow, mask_ssn, log_access.
+type Obligations struct {
+	// Obligations must be fulfilled by the caller, otherwise the decision must not be honored.
+	Obligations []string
+	// Advice can be followed by the caller, but may be ignored.
+	Advice []string
+}
+
+// EnforceExWithObligations explains enforcement by informing matched rules and returns the obligations and advice
+// of the rules that applied to the decision. The effector decides which matched rules apply,
+// e.g. all the matched allow rules under allow-override, see effector.ObligationEffector.
+func (e *Enforcer) EnforceExWithObligations(rvals ...interface{}) (bool, []string, Obligations, error) {
+	explain := []string{}
+	obligations := &Obligations{}
+	result, err := e.enforce(context.Background(), "", &explain, append([]interface{}{obligations}, rvals...)...)
+	return result, explain, *obligations, err
+}
+
+// collect appends the obligations and advice of the rules of ptype that applied to the merged effect.
+func (o *Obligations) collect(eft effector.Effector, expr string, pTokens []string, ptype string, policy [][]string,
+	effects []effector.Effect, matches []float64, effect effector.Effect, explainIndex int) {
+	var indexes []int
+	if obligationEffector, ok := eft.(effector.ObligationEffector); ok {
+		indexes = obligationEffector.ApplicableRules(expr, effects, matches, effect, explainIndex)
+	} else if effect != effector.Indeterminate && explainIndex != -1 {
+		indexes = []int{explainIndex}
+	}
+
+	obligationIndexes := obligationFieldIndexes(pTokens, ptype, constant.ObligationIndex)
+	adviceIndexes := obligationFieldIndexes(pTokens, ptype, constant.AdviceIndex)
+	for _, index := range indexes {
+		if index < 0 || index >= len(policy) {
+			continue
+		}
+		o.Obligations = appendFieldValues(o.Obligations, policy[index], obligationIndexes)
+		o.Advice = appendFieldValues(o.Advice, policy[index], adviceIndexes)
+	}
+}
+
+// obligationFieldIndexes returns the indexes of the tokens named field or prefixed with field + "_", in order.
+func obligationFieldIndexes(tokens []string, ptype string, field string) []int {
+	var indexes []int
+	for i, token := range tokens {
+		name := strings.TrimPrefix(token, ptype+"_")
+		if name == field || strings.HasPrefix(name, field+"_") {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// appendFieldValues appends the non-empty values of rule at indexes to values, skipping duplicates.
+func appendFieldValues(values []string, rule []string, indexes []int) []string {
+	for _, i := range indexes {
+		value := strings.TrimSpace(rule[i])
+		if value == "" {
+			continue
+		}
+		duplicated := false
+		for _, v := range values {
+			if v == value {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/util"
+)
+
+func testEnforceWithObligations(t *testing.T, e *Enforcer, sub, obj, act string, res bool, obligations, advice []string) {
+	t.Helper()
+	myRes, _, myObligations, err := e.EnforceExWithObligations(sub, obj, act)
+	if err != nil {
+		t.Errorf("EnforceExWithObligations Error: %s", err)
+		return
+	}
+	if myRes != res {
+		t.Errorf("%s, %s, %s: %t, supposed to be %t", sub, obj, act, myRes, res)
+	}
+	if !util.ArrayEquals(obligations, myObligations.Obligations) {
+		t.Errorf("%s, %s, %s: obligations %v, supposed to be %v", sub, obj, act, myObligations.Obligations, obligations)
+	}
+	if !util.ArrayEquals(advice, myObligations.Advice) {
+		t.Errorf("%s, %s, %s: advice %v, supposed to be %v", sub, obj, act, myObligations.Advice, advice)
+	}
+}
+
+func TestEnforceWithObligations(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_obligations_model.conf", "examples/rbac_with_obligations_policy.csv")
+
+	// All the matched allow rules apply under allow-override.
+	testEnforceWithObligations(t, e, "alice", "data1", "read", true, []string{"mask_ssn", "watermark"}, []string{"log_access"})
+	testEnforceWithObligations(t, e, "alice", "data2", "read", true, nil, []string{"log_access"})
+	testEnforceWithObligations(t, e, "bob", "data2", "write", false, nil, nil)
+	testEnforceWithObligations(t, e, "bob", "data1", "read", false, nil, nil)
+
+	// The decision and the explanation are the same as EnforceEx.
+	ok, explain, _, _ := e.EnforceExWithObligations("alice", "data1", "read")
+	exOk, exExplain, _ := e.EnforceEx("alice", "data1", "read")
+	if ok != exOk || !util.ArrayEquals(explain, exExplain) {
+		t.Errorf("EnforceExWithObligations: %t, %v, supposed to be %t, %v", ok, explain, exOk, exExplain)
+	}
+
+	// The matched deny rules apply under allow-and-deny.
+	e.GetModel()["e"]["e"].Value = "some(where (p_eft == allow)) && !some(where (p_eft == deny))"
+	_, _ = e.AddPolicy("bob", "data2", "write", "deny", "notify_owner", "")
+	testEnforceWithObligations(t, e, "bob", "data2", "write", false, []string{"audit", "notify_owner"}, nil)
+	testEnforceWithObligations(t, e, "alice", "data1", "read", true, []string{"mask_ssn", "watermark"}, []string{"log_access"})
+}
+
+func TestEnforceWithObligationsPriority(t *testing.T) {
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = priority, sub, obj, act, eft, obligation_1, obligation_2
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = priority(p.eft) || deny
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`)
+	e, _ := NewEnforcer(m)
+	_, _ = e.AddPolicies([][]string{
+		{"10", "alice", "data1", "read", "allow", "mask_ssn", "watermark"},
+		{"20", "data1_reader", "data1", "read", "allow", "log_access", ""},
+	})
+	_, _ = e.AddGroupingPolicy("alice", "data1_reader")
+
+	// Only the deciding rule applies under priority.
+	testEnforceWithObligations(t, e, "alice", "data1", "read", true, []string{"mask_ssn", "watermark"}, nil)
+}
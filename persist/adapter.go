@@ -15,24 +15,23 @@
 package persist
 
 import (
-	"encoding/csv"
+	"io"
 	"strings"
 
 	"github.com/casbin/casbin/v3/model"
 )
 
 // LoadPolicyLine loads a text line as a policy rule to model.
+// The line is read as RFC 4180 CSV, see PolicyReader, so a quoted value can hold commas, quotes and line breaks.
 func LoadPolicyLine(line string, m model.Model) error {
 	if line == "" || strings.HasPrefix(line, "#") {
 		return nil
 	}
 
-	r := csv.NewReader(strings.NewReader(line))
-	r.Comma = ','
-	r.Comment = '#'
-	r.TrimLeadingSpace = true
-
-	tokens, err := r.Read()
+	tokens, err := NewPolicyReader(strings.NewReader(line)).Read()
+	if err == io.EOF {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
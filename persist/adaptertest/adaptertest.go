@@ -0,0 +1,445 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adaptertest provides conformance tests of the persist adapters, e.g.
+//
+//	func TestAdapter(t *testing.T) {
+//		adaptertest.Run(t, adaptertest.Config{
+//			NewAdapter: func(t *testing.T) persist.Adapter {
+//				return myadapter.NewAdapter(newEmptyDatabase(t))
+//			},
+//			Filter: func(pFields []string, gFields []string) interface{} {
+//				return &myadapter.Filter{P: pFields, G: gFields}
+//			},
+//		})
+//	}
+//
+// The tests use the model and the policy of examples/rbac_with_domains_model.conf and examples/rbac_with_domains_policy.csv.
+package adaptertest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/persist"
+)
+
+// Config is the configuration of the conformance tests of an adapter.
+type Config struct {
+	// NewAdapter creates an adapter with an empty storage and auto-save enabled, it is called by each test.
+	NewAdapter func(t *testing.T) persist.Adapter
+	// Filter returns the filter of the adapter loading only the p rules matching pFields
+	// and the g rules matching gFields, where an empty field matches any value,
+	// e.g. &fileadapter.Filter{P: pFields, G: gFields}.
+	// The filtered loading is not tested if it is nil.
+	Filter func(pFields []string, gFields []string) interface{}
+}
+
+// examplePolicy is the policy of examples/rbac_with_domains_policy.csv.
+var examplePolicy = []string{
+	"g, alice, admin, domain1",
+	"g, bob, admin, domain2",
+	"p, admin, domain1, data1, read",
+	"p, admin, domain1, data1, write",
+	"p, admin, domain2, data2, read",
+	"p, admin, domain2, data2, write",
+}
+
+// Run runs the conformance tests of the adapter created by config.NewAdapter.
+// The tests of an optional interface, e.g. persist.BatchAdapter or persist.ContextAdapter, run if the adapter implements it.
+// A batch adapter rejecting a batch, e.g. for a duplicate rule, should leave its storage unchanged.
+func Run(t *testing.T, config Config) {
+	adapter := config.NewAdapter(t)
+
+	t.Run("LoadSavePolicy", func(t *testing.T) {
+		a := newTestAdapter(t, config)
+		testPolicy(t, a, examplePolicy)
+
+		m := newTestModel(t)
+		if err := m.AddPolicy("p", "p", []string{"admin", "domain3", "data3", "read"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.SavePolicy(m); err != nil {
+			t.Fatalf("SavePolicy: %v", err)
+		}
+		testPolicy(t, a, []string{"p, admin, domain3, data3, read"})
+	})
+
+	t.Run("AddRemovePolicy", func(t *testing.T) {
+		a := newTestAdapter(t, config)
+		if err := a.AddPolicy("p", "p", []string{"admin", "domain3", "data3", "read"}); err != nil {
+			t.Fatalf("AddPolicy: %v", err)
+		}
+		if err := a.AddPolicy("g", "g", []string{"eve", "admin", "domain3"}); err != nil {
+			t.Fatalf("AddPolicy: %v", err)
+		}
+		testPolicy(t, a, append(examplePolicy, "g, eve, admin, domain3", "p, admin, domain3, data3, read"))
+
+		if err := a.RemovePolicy("p", "p", []string{"admin", "domain1", "data1", "read"}); err != nil {
+			t.Fatalf("RemovePolicy: %v", err)
+		}
+		if err := a.RemovePolicy("g", "g", []string{"alice", "admin", "domain1"}); err != nil {
+			t.Fatalf("RemovePolicy: %v", err)
+		}
+		testPolicy(t, a, []string{
+			"g, bob, admin, domain2",
+			"g, eve, admin, domain3",
+			"p, admin, domain1, data1, write",
+			"p, admin, domain2, data2, read",
+			"p, admin, domain2, data2, write",
+			"p, admin, domain3, data3, read",
+		})
+	})
+
+	t.Run("RemoveFilteredPolicy", func(t *testing.T) {
+		a := newTestAdapter(t, config)
+		if err := a.RemoveFilteredPolicy("p", "p", 1, "domain1"); err != nil {
+			t.Fatalf("RemoveFilteredPolicy: %v", err)
+		}
+		// an empty field value matches any value
+		if err := a.RemoveFilteredPolicy("p", "p", 0, "admin", "", "", "write"); err != nil {
+			t.Fatalf("RemoveFilteredPolicy: %v", err)
+		}
+		if err := a.RemoveFilteredPolicy("g", "g", 2, "domain2"); err != nil {
+			t.Fatalf("RemoveFilteredPolicy: %v", err)
+		}
+		testPolicy(t, a, []string{
+			"g, alice, admin, domain1",
+			"p, admin, domain2, data2, read",
+		})
+	})
+
+	if _, ok := adapter.(persist.BatchAdapter); ok {
+		t.Run("AddRemovePolicies", func(t *testing.T) {
+			a := newTestAdapter(t, config).(persist.BatchAdapter)
+			if err := a.AddPolicies("p", "p", [][]string{{"admin", "domain3", "data3", "read"}, {"admin", "domain3", "data3", "write"}}); err != nil {
+				t.Fatalf("AddPolicies: %v", err)
+			}
+			testPolicy(t, a, append(examplePolicy, "p, admin, domain3, data3, read", "p, admin, domain3, data3, write"))
+
+			if err := a.RemovePolicies("p", "p", [][]string{{"admin", "domain1", "data1", "read"}, {"admin", "domain3", "data3", "write"}}); err != nil {
+				t.Fatalf("RemovePolicies: %v", err)
+			}
+			if err := a.RemovePolicies("g", "g", [][]string{{"alice", "admin", "domain1"}, {"bob", "admin", "domain2"}}); err != nil {
+				t.Fatalf("RemovePolicies: %v", err)
+			}
+			testPolicy(t, a, []string{
+				"p, admin, domain1, data1, write",
+				"p, admin, domain2, data2, read",
+				"p, admin, domain2, data2, write",
+				"p, admin, domain3, data3, read",
+			})
+		})
+
+		t.Run("BatchAtomicity", func(t *testing.T) {
+			testBatchAtomicity(t, config)
+		})
+	}
+
+	if _, ok := adapter.(persist.UpdatableAdapter); ok {
+		t.Run("UpdatePolicies", func(t *testing.T) {
+			a := newTestAdapter(t, config).(persist.UpdatableAdapter)
+			if err := a.UpdatePolicy("p", "p", []string{"admin", "domain1", "data1", "read"}, []string{"admin", "domain1", "data3", "read"}); err != nil {
+				t.Fatalf("UpdatePolicy: %v", err)
+			}
+			if err := a.UpdatePolicies("g", "g", [][]string{{"alice", "admin", "domain1"}, {"bob", "admin", "domain2"}}, [][]string{{"alice", "admin", "domain2"}, {"bob", "admin", "domain1"}}); err != nil {
+				t.Fatalf("UpdatePolicies: %v", err)
+			}
+			testPolicy(t, a, []string{
+				"g, alice, admin, domain2",
+				"g, bob, admin, domain1",
+				"p, admin, domain1, data1, write",
+				"p, admin, domain1, data3, read",
+				"p, admin, domain2, data2, read",
+				"p, admin, domain2, data2, write",
+			})
+		})
+
+		t.Run("UpdateFilteredPolicies", func(t *testing.T) {
+			a := newTestAdapter(t, config).(persist.UpdatableAdapter)
+			oldRules, err := a.UpdateFilteredPolicies("p", "p", [][]string{{"admin", "domain2", "data3", "read"}}, 1, "domain2")
+			if err != nil {
+				t.Fatalf("UpdateFilteredPolicies: %v", err)
+			}
+			testRules(t, "UpdateFilteredPolicies", "p", oldRules, []string{
+				"p, admin, domain2, data2, read",
+				"p, admin, domain2, data2, write",
+			})
+			testPolicy(t, a, []string{
+				"g, alice, admin, domain1",
+				"g, bob, admin, domain2",
+				"p, admin, domain1, data1, read",
+				"p, admin, domain1, data1, write",
+				"p, admin, domain2, data3, read",
+			})
+
+			oldRules, err = a.UpdateFilteredPolicies("g", "g", [][]string{{"eve", "admin", "domain1"}}, 0, "alice", "", "domain1")
+			if err != nil {
+				t.Fatalf("UpdateFilteredPolicies: %v", err)
+			}
+			testRules(t, "UpdateFilteredPolicies", "g", oldRules, []string{"g, alice, admin, domain1"})
+			testPolicy(t, a, []string{
+				"g, bob, admin, domain2",
+				"g, eve, admin, domain1",
+				"p, admin, domain1, data1, read",
+				"p, admin, domain1, data1, write",
+				"p, admin, domain2, data3, read",
+			})
+		})
+	}
+
+	if _, ok := adapter.(persist.FilteredAdapter); ok && config.Filter != nil {
+		t.Run("LoadFilteredPolicy", func(t *testing.T) {
+			a := newTestAdapter(t, config).(persist.FilteredAdapter)
+			m := newTestModel(t)
+			if err := a.LoadFilteredPolicy(m, config.Filter([]string{"", "domain1"}, []string{"", "", "domain1"})); err != nil {
+				t.Fatalf("LoadFilteredPolicy: %v", err)
+			}
+			testModelPolicy(t, "LoadFilteredPolicy", m, []string{
+				"g, alice, admin, domain1",
+				"p, admin, domain1, data1, read",
+				"p, admin, domain1, data1, write",
+			})
+			if !a.IsFiltered() {
+				t.Error("IsFiltered() = false after LoadFilteredPolicy, supposed to be true")
+			}
+
+			m = newTestModel(t)
+			if err := a.LoadFilteredPolicy(m, config.Filter([]string{"admin", "", "", "write"}, nil)); err != nil {
+				t.Fatalf("LoadFilteredPolicy: %v", err)
+			}
+			testModelPolicy(t, "LoadFilteredPolicy", m, []string{
+				"g, alice, admin, domain1",
+				"g, bob, admin, domain2",
+				"p, admin, domain1, data1, write",
+				"p, admin, domain2, data2, write",
+			})
+
+			testPolicy(t, a, examplePolicy)
+			if a.IsFiltered() {
+				t.Error("IsFiltered() = true after LoadPolicy, supposed to be false")
+			}
+		})
+	}
+
+	if _, ok := adapter.(persist.ContextAdapter); ok {
+		t.Run("Context", func(t *testing.T) {
+			testContext(t, config)
+		})
+	}
+}
+
+// testBatchAtomicity checks that a failing AddPolicies or RemovePolicies leaves the storage unchanged.
+// The batches hold a valid rule followed by a duplicate or a missing rule, the test is skipped
+// if the adapter accepts both batches, as they cannot fail then.
+func testBatchAtomicity(t *testing.T, config Config) {
+	failed := false
+
+	a := newTestAdapter(t, config).(persist.BatchAdapter)
+	err := a.AddPolicies("p", "p", [][]string{{"admin", "domain3", "data3", "read"}, {"admin", "domain1", "data1", "read"}})
+	if err != nil {
+		failed = true
+		testPolicy(t, a, examplePolicy)
+	}
+
+	a = newTestAdapter(t, config).(persist.BatchAdapter)
+	err = a.RemovePolicies("p", "p", [][]string{{"admin", "domain1", "data1", "read"}, {"admin", "domain3", "data3", "read"}})
+	if err != nil {
+		failed = true
+		testPolicy(t, a, examplePolicy)
+	}
+
+	if !failed {
+		t.Skip("the adapter accepts duplicate and missing rules in batches")
+	}
+}
+
+// testContext checks the context methods of the adapter, which should return the error of a canceled context
+// without changing the storage.
+func testContext(t *testing.T, config Config) {
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	a := newTestAdapter(t, config)
+	ca := a.(persist.ContextAdapter)
+	m := newTestModel(t)
+	if err := ca.LoadPolicyCtx(ctx, m); err != nil {
+		t.Fatalf("LoadPolicyCtx: %v", err)
+	}
+	testModelPolicy(t, "LoadPolicyCtx", m, examplePolicy)
+	if err := ca.AddPolicyCtx(ctx, "p", "p", []string{"admin", "domain3", "data3", "read"}); err != nil {
+		t.Fatalf("AddPolicyCtx: %v", err)
+	}
+	if err := ca.RemovePolicyCtx(ctx, "p", "p", []string{"admin", "domain3", "data3", "read"}); err != nil {
+		t.Fatalf("RemovePolicyCtx: %v", err)
+	}
+	if err := ca.RemoveFilteredPolicyCtx(ctx, "p", "p", 1, "domain3"); err != nil {
+		t.Fatalf("RemoveFilteredPolicyCtx: %v", err)
+	}
+	testPolicy(t, a, examplePolicy)
+
+	testCanceled(t, "LoadPolicyCtx", ca.LoadPolicyCtx(canceled, newTestModel(t)))
+	testCanceled(t, "SavePolicyCtx", ca.SavePolicyCtx(canceled, newTestModel(t)))
+	testCanceled(t, "AddPolicyCtx", ca.AddPolicyCtx(canceled, "p", "p", []string{"admin", "domain3", "data3", "read"}))
+	testCanceled(t, "RemovePolicyCtx", ca.RemovePolicyCtx(canceled, "p", "p", []string{"admin", "domain1", "data1", "read"}))
+	testCanceled(t, "RemoveFilteredPolicyCtx", ca.RemoveFilteredPolicyCtx(canceled, "p", "p", 1, "domain1"))
+
+	if ba, ok := a.(persist.ContextBatchAdapter); ok {
+		testCanceled(t, "AddPoliciesCtx", ba.AddPoliciesCtx(canceled, "p", "p", [][]string{{"admin", "domain3", "data3", "read"}}))
+		testCanceled(t, "RemovePoliciesCtx", ba.RemovePoliciesCtx(canceled, "p", "p", [][]string{{"admin", "domain1", "data1", "read"}}))
+	}
+	if ua, ok := a.(persist.ContextUpdatableAdapter); ok {
+		testCanceled(t, "UpdatePolicyCtx", ua.UpdatePolicyCtx(canceled, "p", "p", []string{"admin", "domain1", "data1", "read"}, []string{"admin", "domain1", "data3", "read"}))
+		testCanceled(t, "UpdatePoliciesCtx", ua.UpdatePoliciesCtx(canceled, "p", "p", [][]string{{"admin", "domain1", "data1", "read"}}, [][]string{{"admin", "domain1", "data3", "read"}}))
+		_, err := ua.UpdateFilteredPoliciesCtx(canceled, "p", "p", [][]string{{"admin", "domain1", "data3", "read"}}, 1, "domain1")
+		testCanceled(t, "UpdateFilteredPoliciesCtx", err)
+	}
+	if fa, ok := a.(persist.ContextFilteredAdapter); ok && config.Filter != nil {
+		testCanceled(t, "LoadFilteredPolicyCtx", fa.LoadFilteredPolicyCtx(canceled, newTestModel(t), config.Filter([]string{"", "domain1"}, nil)))
+
+		m := newTestModel(t)
+		if err := fa.LoadFilteredPolicyCtx(ctx, m, config.Filter([]string{"", "domain1"}, []string{"", "", "domain1"})); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx: %v", err)
+		}
+		testModelPolicy(t, "LoadFilteredPolicyCtx", m, []string{
+			"g, alice, admin, domain1",
+			"p, admin, domain1, data1, read",
+			"p, admin, domain1, data1, write",
+		})
+		if !fa.IsFilteredCtx(ctx) {
+			t.Error("IsFilteredCtx() = false after LoadFilteredPolicyCtx, supposed to be true")
+		}
+	}
+
+	testPolicy(t, a, examplePolicy)
+}
+
+func testCanceled(t *testing.T, method string, err error) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s with a canceled context supposed to return an error", method)
+	}
+}
+
+// examplesDir returns the examples directory of the module, next to the source of this package.
+func examplesDir(t *testing.T) string {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("cannot find the examples directory")
+	}
+	return filepath.Join(filepath.Dir(file), "..", "..", "examples")
+}
+
+func newTestModel(t *testing.T) model.Model {
+	t.Helper()
+	m, err := model.NewModelFromFile(filepath.Join(examplesDir(t), "rbac_with_domains_model.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// newTestAdapter returns an adapter whose storage holds the example policy.
+func newTestAdapter(t *testing.T, config Config) persist.Adapter {
+	t.Helper()
+	m := newTestModel(t)
+	f, err := os.Open(filepath.Join(examplesDir(t), "rbac_with_domains_policy.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r := persist.NewPolicyReader(f)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = persist.LoadPolicyArray(record, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := config.NewAdapter(t)
+	if fa, ok := a.(persist.FilteredAdapter); ok && fa.IsFiltered() {
+		// a filtered policy cannot be saved, the empty storage is loaded first
+		if err = fa.LoadPolicy(newTestModel(t)); err != nil {
+			t.Fatalf("LoadPolicy: %v", err)
+		}
+	}
+	if err = a.SavePolicy(m); err != nil {
+		t.Fatalf("SavePolicy: %v", err)
+	}
+	return a
+}
+
+// testPolicy checks the policy loaded from the storage of the adapter.
+func testPolicy(t *testing.T, a persist.Adapter, expected []string) {
+	t.Helper()
+	m := newTestModel(t)
+	if err := a.LoadPolicy(m); err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	testModelPolicy(t, "LoadPolicy", m, expected)
+}
+
+func testModelPolicy(t *testing.T, method string, m model.Model, expected []string) {
+	t.Helper()
+	var policy []string
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			for _, rule := range ast.Policy {
+				policy = append(policy, formatRule(ptype, rule))
+			}
+		}
+	}
+	testStrings(t, method, policy, expected)
+}
+
+func testRules(t *testing.T, method string, ptype string, rules [][]string, expected []string) {
+	t.Helper()
+	var policy []string
+	for _, rule := range rules {
+		policy = append(policy, formatRule(ptype, rule))
+	}
+	testStrings(t, method, policy, expected)
+}
+
+func testStrings(t *testing.T, method string, policy []string, expected []string) {
+	t.Helper()
+	policy = append([]string(nil), policy...)
+	expected = append([]string(nil), expected...)
+	sort.Strings(policy)
+	sort.Strings(expected)
+	if strings.Join(policy, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("%s: got rules\n%s\nsupposed to be\n%s", method, strings.Join(policy, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func formatRule(ptype string, rule []string) string {
+	return fmt.Sprintf("%s, %s", ptype, strings.Join(rule, ", "))
+}
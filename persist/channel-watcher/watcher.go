@@ -0,0 +1,193 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channelwatcher
+
+import (
+	"sync"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/persist"
+)
+
+// Channel connects the watchers of the enforcers of a process,
+// a message of a watcher is delivered to the other watchers of the channel, e.g.
+//
+//	ch := channelwatcher.NewChannel()
+//	_ = e1.SetWatcher(ch.NewWatcher())
+//	_ = e2.SetWatcher(ch.NewWatcher())
+//
+// The changes of e1 are applied to e2 by the callback set by SetWatcher, see casbin.NewWatcherCallback.
+type Channel struct {
+	mutex    sync.RWMutex
+	watchers map[*Watcher]struct{}
+}
+
+// NewChannel creates a channel without watcher.
+func NewChannel() *Channel {
+	return &Channel{watchers: make(map[*Watcher]struct{})}
+}
+
+// NewWatcher creates a watcher of the channel.
+func (c *Channel) NewWatcher() *Watcher {
+	w := &Watcher{channel: c}
+	w.cond = sync.NewCond(&w.mutex)
+
+	c.mutex.Lock()
+	c.watchers[w] = struct{}{}
+	c.mutex.Unlock()
+
+	go w.run()
+	return w
+}
+
+func (c *Channel) publish(from *Watcher, message string) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for w := range c.watchers {
+		if w != from {
+			w.push(message)
+		}
+	}
+}
+
+// Watcher is a watcher of a channel, implementing persist.Watcher, persist.WatcherEx, persist.UpdatableWatcher
+// and persist.InstanceWatcher. Its messages are encoded by persist.EncodeWatcherMessage.
+// The update callback is called in a goroutine of the watcher, in the order of the messages.
+type Watcher struct {
+	channel  *Channel
+	sequence persist.WatcherSequence
+	// sendMutex keeps the messages in the order of their sequence numbers
+	sendMutex sync.Mutex
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	messages  []string
+	callback  func(string)
+	closed    bool
+}
+
+func (w *Watcher) push(message string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return
+	}
+	w.messages = append(w.messages, message)
+	w.cond.Signal()
+}
+
+func (w *Watcher) run() {
+	for {
+		w.mutex.Lock()
+		for len(w.messages) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.closed {
+			w.mutex.Unlock()
+			return
+		}
+		message := w.messages[0]
+		w.messages = w.messages[1:]
+		callback := w.callback
+		w.mutex.Unlock()
+
+		if callback != nil {
+			callback(message)
+		}
+	}
+}
+
+func (w *Watcher) publish(message persist.WatcherMessage) error {
+	w.sendMutex.Lock()
+	defer w.sendMutex.Unlock()
+
+	w.sequence.Stamp(&message)
+	data, err := persist.EncodeWatcherMessage(message)
+	if err != nil {
+		return err
+	}
+	w.channel.publish(w, data)
+	return nil
+}
+
+// SetUpdateCallback sets the callback function called with the messages of the other watchers of the channel.
+func (w *Watcher) SetUpdateCallback(callback func(string)) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// SetInstanceID sets the instance ID of the enforcer of the watcher, written in its messages.
+func (w *Watcher) SetInstanceID(id string) {
+	w.sequence.SetInstanceID(id)
+}
+
+// Update notifies the other watchers of the channel that the policy has been changed.
+func (w *Watcher) Update() error {
+	return w.publish(persist.WatcherMessage{Method: persist.Update})
+}
+
+// Close removes the watcher from the channel, the callback function will not be called any more.
+func (w *Watcher) Close() {
+	w.channel.mutex.Lock()
+	delete(w.channel.watchers, w)
+	w.channel.mutex.Unlock()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	w.messages = nil
+	w.cond.Signal()
+}
+
+// UpdateForAddPolicy notifies the other watchers of the channel that a rule has been added.
+func (w *Watcher) UpdateForAddPolicy(sec, ptype string, params ...string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForAddPolicy, Sec: sec, PType: ptype, Rules: [][]string{params}})
+}
+
+// UpdateForRemovePolicy notifies the other watchers of the channel that a rule has been removed.
+func (w *Watcher) UpdateForRemovePolicy(sec, ptype string, params ...string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForRemovePolicy, Sec: sec, PType: ptype, Rules: [][]string{params}})
+}
+
+// UpdateForRemoveFilteredPolicy notifies the other watchers of the channel that the rules matching a filter have been removed.
+func (w *Watcher) UpdateForRemoveFilteredPolicy(sec, ptype string, fieldIndex int, fieldValues ...string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForRemoveFilteredPolicy, Sec: sec, PType: ptype, FieldIndex: fieldIndex, FieldValues: fieldValues})
+}
+
+// UpdateForSavePolicy notifies the other watchers of the channel that the policy has been saved.
+func (w *Watcher) UpdateForSavePolicy(model model.Model) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForSavePolicy})
+}
+
+// UpdateForAddPolicies notifies the other watchers of the channel that rules have been added.
+func (w *Watcher) UpdateForAddPolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForAddPolicies, Sec: sec, PType: ptype, Rules: rules})
+}
+
+// UpdateForRemovePolicies notifies the other watchers of the channel that rules have been removed.
+func (w *Watcher) UpdateForRemovePolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForRemovePolicies, Sec: sec, PType: ptype, Rules: rules})
+}
+
+// UpdateForUpdatePolicy notifies the other watchers of the channel that a rule has been updated.
+func (w *Watcher) UpdateForUpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForUpdatePolicy, Sec: sec, PType: ptype, Rules: [][]string{oldRule}, NewRules: [][]string{newRule}})
+}
+
+// UpdateForUpdatePolicies notifies the other watchers of the channel that rules have been updated.
+func (w *Watcher) UpdateForUpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return w.publish(persist.WatcherMessage{Method: persist.UpdateForUpdatePolicies, Sec: sec, PType: ptype, Rules: oldRules, NewRules: newRules})
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channelwatcher_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v3"
+	"github.com/casbin/casbin/v3/persist"
+	channelwatcher "github.com/casbin/casbin/v3/persist/channel-watcher"
+	"github.com/casbin/casbin/v3/persist/watchertest"
+)
+
+func TestWatcherConformance(t *testing.T) {
+	watchertest.Run(t, watchertest.Config{
+		NewWatchers: func(t *testing.T) (persist.Watcher, persist.Watcher) {
+			ch := channelwatcher.NewChannel()
+			return ch.NewWatcher(), ch.NewWatcher()
+		},
+	})
+}
+
+func TestWatcherMessage(t *testing.T) {
+	ch := channelwatcher.NewChannel()
+	w1 := ch.NewWatcher()
+	defer w1.Close()
+	w2 := ch.NewWatcher()
+	defer w2.Close()
+
+	messages := make(chan string, 1)
+	_ = w1.SetUpdateCallback(func(message string) {
+		t.Errorf("a watcher received its own message %q", message)
+	})
+	_ = w2.SetUpdateCallback(func(message string) { messages <- message })
+	w1.SetInstanceID("node1")
+
+	if err := w1.UpdateForUpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case message := <-messages:
+		m, err := persist.DecodeWatcherMessage(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Method != persist.UpdateForUpdatePolicy || m.Sec != "p" || m.PType != "p" ||
+			len(m.Rules) != 1 || m.Rules[0][2] != "read" || len(m.NewRules) != 1 || m.NewRules[0][2] != "write" ||
+			m.Source != "node1" || m.Sequence != 1 {
+			t.Errorf("unexpected message %s", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the message was not received")
+	}
+}
+
+func TestWatcherEnforcers(t *testing.T) {
+	ch := channelwatcher.NewChannel()
+	var enforcers []*casbin.Enforcer
+	reloaded := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		e, err := casbin.NewEnforcer("../../examples/rbac_model.conf", "../../examples/rbac_policy.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := ch.NewWatcher()
+		defer w.Close()
+		if err = e.SetWatcher(w); err != nil {
+			t.Fatal(err)
+		}
+		i := i
+		_ = w.SetUpdateCallback(func(string) { reloaded <- i })
+		enforcers = append(enforcers, e)
+	}
+
+	if _, err := enforcers[0].AddPolicy("eve", "data3", "read"); err != nil {
+		t.Fatal(err)
+	}
+	received := map[int]bool{}
+	for len(received) < 2 {
+		select {
+		case i := <-reloaded:
+			received[i] = true
+		case <-time.After(time.Second):
+			t.Fatalf("the other enforcers were not notified: %v", received)
+		}
+	}
+	if received[0] {
+		t.Error("the enforcer changing the policy was notified")
+	}
+}
+
+func TestWatcherSync(t *testing.T) {
+	ch := channelwatcher.NewChannel()
+	e1, _ := casbin.NewEnforcer("../../examples/rbac_model.conf", "../../examples/rbac_policy.csv")
+	e2, _ := casbin.NewSyncedEnforcer("../../examples/rbac_model.conf", "../../examples/rbac_policy.csv")
+	w1 := ch.NewWatcher()
+	defer w1.Close()
+	w2 := ch.NewWatcher()
+	defer w2.Close()
+	_ = e1.SetWatcher(w1)
+	_ = e2.SetWatcher(w2)
+
+	if _, err := e1.AddPolicy("eve", "data3", "read"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e1.UpdatePolicy([]string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		added, _ := e2.HasPolicy("eve", "data3", "read")
+		updated, _ := e2.HasPolicy("alice", "data1", "write")
+		if added && updated {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the changes of e1 were not applied to e2")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
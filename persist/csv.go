@@ -0,0 +1,146 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PolicyReader reads policy records from a RFC 4180 CSV text, a record is the policy type followed by the rule,
+// e.g. `p, alice, "keyMatch(r.obj, p.obj)", read`. A quoted field can hold commas, quotes written as "" and line breaks.
+// For compatibility with the unquoted policy files, the spaces around the fields are trimmed,
+// a quote inside an unquoted field is kept as is, and empty lines and lines starting with "#" are skipped.
+type PolicyReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewPolicyReader is the constructor for PolicyReader.
+func NewPolicyReader(r io.Reader) *PolicyReader {
+	return &PolicyReader{r: bufio.NewReader(r)}
+}
+
+// Read reads the next record, it returns io.EOF when there is no more record.
+// After a syntax error, the reading can go on from the next line.
+func (r *PolicyReader) Read() ([]string, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return r.parseRecord(line)
+	}
+}
+
+// readLine reads the next line without its line break.
+func (r *PolicyReader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	r.line++
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+func (r *PolicyReader) parseRecord(line string) ([]string, error) {
+	startLine := r.line
+	var record []string
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if !strings.HasPrefix(line, `"`) {
+			i := strings.IndexByte(line, ',')
+			if i == -1 {
+				return append(record, strings.TrimRight(line, " \t")), nil
+			}
+			record = append(record, strings.TrimRight(line[:i], " \t"))
+			line = line[i+1:]
+			continue
+		}
+
+		// a quoted field, which may span several lines
+		var field strings.Builder
+		line = line[1:]
+		for {
+			i := strings.IndexByte(line, '"')
+			if i == -1 {
+				field.WriteString(line)
+				next, err := r.readLine()
+				if err == io.EOF {
+					return nil, fmt.Errorf("line %d: unterminated quoted field", startLine)
+				} else if err != nil {
+					return nil, err
+				}
+				field.WriteString("\n")
+				line = next
+				continue
+			}
+			field.WriteString(line[:i])
+			if strings.HasPrefix(line[i+1:], `"`) {
+				field.WriteString(`"`)
+				line = line[i+2:]
+				continue
+			}
+			line = line[i+1:]
+			break
+		}
+		record = append(record, field.String())
+
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return record, nil
+		}
+		if line[0] != ',' {
+			return nil, fmt.Errorf("line %d: unexpected %q after a quoted field", r.line, line[0])
+		}
+		line = line[1:]
+	}
+}
+
+// ReadPolicyRecords reads all the records of a policy text, see PolicyReader.
+func ReadPolicyRecords(text string) ([][]string, error) {
+	r := NewPolicyReader(strings.NewReader(text))
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// FormatPolicyRecord formats a record as a line of a policy file that PolicyReader reads back as is,
+// e.g. ["p", "alice", "a, b"] gives `p, alice, "a, b"`.
+// A field is quoted if it holds a comma, a quote or a line break, or starts or ends with a space.
+func FormatPolicyRecord(record []string) string {
+	fields := make([]string, len(record))
+	for i, field := range record {
+		if strings.ContainsAny(field, ",\"\r\n") || strings.TrimSpace(field) != field {
+			field = `"` + strings.Replace(field, `"`, `""`, -1) + `"`
+		}
+		fields[i] = field
+	}
+	return strings.Join(fields, ", ")
+}
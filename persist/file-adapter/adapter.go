@@ -15,38 +15,46 @@
 package fileadapter
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/casbin/casbin/v3/model"
 	"github.com/casbin/casbin/v3/persist"
-	"github.com/casbin/casbin/v3/util"
 )
 
 // Adapter is the file adapter for Casbin.
 // It can load policy from file or save policy to file.
+// With auto-save enabled, it also applies the changes of the policy to the file, see EnableAutoSave.
 type Adapter struct {
 	filePath string
+	autoSave bool
+	mutex    sync.Mutex
 }
 
-func (a *Adapter) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
-	return errors.New("not implemented")
-}
-
-func (a *Adapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
-	return errors.New("not implemented")
+// NewAdapter is the constructor for Adapter.
+func NewAdapter(filePath string) *Adapter {
+	return &Adapter{filePath: filePath}
 }
 
-func (a *Adapter) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
-	return nil, errors.New("not implemented")
+// EnableAutoSave controls whether the changes of the policy are applied to the file.
+// It is disabled by default, and the changes are kept in memory until SavePolicy is called.
+// When enabled, every change rewrites the file atomically under a lock shared with other processes,
+// keeping the comments and the order of the untouched lines.
+func (a *Adapter) EnableAutoSave(autoSave bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.autoSave = autoSave
 }
 
-// NewAdapter is the constructor for Adapter.
-func NewAdapter(filePath string) *Adapter {
-	return &Adapter{filePath: filePath}
+func (a *Adapter) isAutoSave() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.autoSave
 }
 
 // LoadPolicy loads all policy rules from the storage.
@@ -55,7 +63,7 @@ func (a *Adapter) LoadPolicy(model model.Model) error {
 		return errors.New("invalid file path, file path cannot be empty")
 	}
 
-	return a.loadPolicyFile(model, persist.LoadPolicyLine)
+	return a.loadPolicyFile(model, persist.LoadPolicyArray)
 }
 
 // SavePolicy saves all policy rules to the storage.
@@ -68,16 +76,14 @@ func (a *Adapter) SavePolicy(model model.Model) error {
 
 	for ptype, ast := range model["p"] {
 		for _, rule := range ast.Policy {
-			tmp.WriteString(ptype + ", ")
-			tmp.WriteString(util.ArrayToString(rule))
+			tmp.WriteString(persist.FormatPolicyRecord(append([]string{ptype}, rule...)))
 			tmp.WriteString("\n")
 		}
 	}
 
 	for ptype, ast := range model["g"] {
 		for _, rule := range ast.Policy {
-			tmp.WriteString(ptype + ", ")
-			tmp.WriteString(util.ArrayToString(rule))
+			tmp.WriteString(persist.FormatPolicyRecord(append([]string{ptype}, rule...)))
 			tmp.WriteString("\n")
 		}
 	}
@@ -85,65 +91,161 @@ func (a *Adapter) SavePolicy(model model.Model) error {
 	return a.savePolicyFile(strings.TrimRight(tmp.String(), "\n"))
 }
 
-func (a *Adapter) loadPolicyFile(model model.Model, handler func(string, model.Model) error) error {
+func (a *Adapter) loadPolicyFile(model model.Model, handler func([]string, model.Model) error) error {
 	f, err := os.Open(a.filePath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		err = handler(line, model)
+	r := persist.NewPolicyReader(f)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = handler(record, model)
 		if err != nil {
 			return err
 		}
 	}
-	return scanner.Err()
 }
 
 func (a *Adapter) savePolicyFile(text string) error {
-	f, err := os.Create(a.filePath)
-	if err != nil {
-		return err
-	}
-	w := bufio.NewWriter(f)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 
-	_, err = w.WriteString(text)
+	unlock, err := lockFile(a.filePath)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	err = w.Flush()
-	if err != nil {
-		return err
-	}
-
-	return f.Close()
+	return writeFileAtomic(a.filePath, []byte(text))
 }
 
 // AddPolicy adds a policy rule to the storage.
 func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
-	return errors.New("not implemented")
+	return a.AddPolicies(sec, ptype, [][]string{rule})
 }
 
 // AddPolicies adds policy rules to the storage.
 func (a *Adapter) AddPolicies(sec string, ptype string, rules [][]string) error {
-	return errors.New("not implemented")
+	if !a.isAutoSave() {
+		return errors.New("not implemented")
+	}
+	return a.updatePolicyFile(func(lines []*policyLine) ([]*policyLine, error) {
+		for _, rule := range rules {
+			lines = append(lines, newPolicyLine(ptype, rule))
+		}
+		return lines, nil
+	})
 }
 
 // RemovePolicy removes a policy rule from the storage.
 func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
-	return errors.New("not implemented")
+	return a.RemovePolicies(sec, ptype, [][]string{rule})
 }
 
 // RemovePolicies removes policy rules from the storage.
 func (a *Adapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
-	return errors.New("not implemented")
+	if !a.isAutoSave() {
+		return errors.New("not implemented")
+	}
+	return a.updatePolicyFile(func(lines []*policyLine) ([]*policyLine, error) {
+		kept := lines[:0]
+		for _, line := range lines {
+			if !line.matchesAny(ptype, rules) {
+				kept = append(kept, line)
+			}
+		}
+		return kept, nil
+	})
 }
 
 // RemoveFilteredPolicy removes policy rules that match the filter from the storage.
 func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
-	return errors.New("not implemented")
+	if !a.isAutoSave() {
+		return errors.New("not implemented")
+	}
+	return a.updatePolicyFile(func(lines []*policyLine) ([]*policyLine, error) {
+		kept := lines[:0]
+		for _, line := range lines {
+			if !line.matchesFilter(ptype, fieldIndex, fieldValues) {
+				kept = append(kept, line)
+			}
+		}
+		return kept, nil
+	})
+}
+
+// UpdatePolicy updates a policy rule of the storage, in place.
+func (a *Adapter) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return a.UpdatePolicies(sec, ptype, [][]string{oldRule}, [][]string{newRule})
+}
+
+// UpdatePolicies updates policy rules of the storage, in place.
+// The file is left untouched if an old rule is not in it.
+func (a *Adapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	if !a.isAutoSave() {
+		return errors.New("not implemented")
+	}
+	if len(oldRules) != len(newRules) {
+		return errors.New("the number of old rules and new rules should be the same")
+	}
+	return a.updatePolicyFile(func(lines []*policyLine) ([]*policyLine, error) {
+		for i, oldRule := range oldRules {
+			updated := false
+			for j, line := range lines {
+				if line.matches(ptype, oldRule) {
+					lines[j] = newPolicyLine(ptype, newRules[i])
+					updated = true
+					break
+				}
+			}
+			if !updated {
+				return nil, fmt.Errorf("the rule %v of %s is not in the policy file", oldRule, ptype)
+			}
+		}
+		return lines, nil
+	})
+}
+
+// UpdateFilteredPolicies deletes the policy rules that match the filter and adds the new rules
+// in place of the first of them, it returns the deleted rules.
+func (a *Adapter) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	if !a.isAutoSave() {
+		return nil, errors.New("not implemented")
+	}
+	var oldRules [][]string
+	err := a.updatePolicyFile(func(lines []*policyLine) ([]*policyLine, error) {
+		var kept []*policyLine
+		position := -1
+		for _, line := range lines {
+			if line.matchesFilter(ptype, fieldIndex, fieldValues) {
+				if position == -1 {
+					position = len(kept)
+				}
+				oldRules = append(oldRules, line.tokens[1:])
+				continue
+			}
+			kept = append(kept, line)
+		}
+		if position == -1 {
+			position = len(kept)
+		}
+
+		added := make([]*policyLine, 0, len(newRules))
+		for _, rule := range newRules {
+			added = append(added, newPolicyLine(ptype, rule))
+		}
+		return append(kept[:position], append(added, kept[position:]...)...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oldRules, nil
 }
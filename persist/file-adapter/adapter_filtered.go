@@ -15,8 +15,8 @@
 package fileadapter
 
 import (
-	"bufio"
 	"errors"
+	"io"
 	"os"
 	"strings"
 
@@ -70,34 +70,39 @@ func (a *FilteredAdapter) LoadFilteredPolicy(model model.Model, filter interface
 	if !ok {
 		return errors.New("invalid filter type")
 	}
-	err := a.loadFilteredPolicyFile(model, filterValue, persist.LoadPolicyLine)
+	err := a.loadFilteredPolicyFile(model, filterValue, persist.LoadPolicyArray)
 	if err == nil {
 		a.filtered = true
 	}
 	return err
 }
 
-func (a *FilteredAdapter) loadFilteredPolicyFile(model model.Model, filter *Filter, handler func(string, model.Model) error) error {
+func (a *FilteredAdapter) loadFilteredPolicyFile(model model.Model, filter *Filter, handler func([]string, model.Model) error) error {
 	f, err := os.Open(a.filePath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	r := persist.NewPolicyReader(f)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-		if filterLine(line, filter) {
+		if filterRecord(record, filter) {
 			continue
 		}
 
-		err = handler(line, model)
+		err = handler(record, model)
 		if err != nil {
 			return err
 		}
 	}
-	return scanner.Err()
 }
 
 // IsFiltered returns true if the loaded policy has been filtered.
@@ -113,16 +118,15 @@ func (a *FilteredAdapter) SavePolicy(model model.Model) error {
 	return a.Adapter.SavePolicy(model)
 }
 
-func filterLine(line string, filter *Filter) bool {
+func filterRecord(record []string, filter *Filter) bool {
 	if filter == nil {
 		return false
 	}
-	p := strings.Split(line, ",")
-	if len(p) == 0 {
+	if len(record) == 0 {
 		return true
 	}
 	var filterSlice []string
-	switch strings.TrimSpace(p[0]) {
+	switch strings.TrimSpace(record[0]) {
 	case "p":
 		filterSlice = filter.P
 	case "g":
@@ -138,7 +142,7 @@ func filterLine(line string, filter *Filter) bool {
 	case "g5":
 		filterSlice = filter.G5
 	}
-	return filterWords(p, filterSlice)
+	return filterWords(record, filterSlice)
 }
 
 func filterWords(line []string, filter []string) bool {
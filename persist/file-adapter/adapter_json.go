@@ -0,0 +1,501 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/persist"
+	"github.com/casbin/casbin/v3/util"
+)
+
+// JSONAdapter is the JSON file adapter for Casbin.
+// The rules are grouped by section and policy type, and a rule is either an array of values
+// or an object naming its values with the tokens of the policy definition, e.g.
+//
+//	{
+//	  "p": {"p": [["alice", "data1", "read"], {"sub": "bob", "obj": "data2", "act": "write"}]},
+//	  "g": {"g": [["alice", "data2_admin"]]}
+//	}
+//
+// Only the rules of the policy definitions ("p" section) can be written and read as objects,
+// the rules of the role definitions are always arrays.
+// With auto-save enabled, it also applies the changes of the policy to the file, see EnableAutoSave.
+// It does not implement persist.FilteredAdapter, so the whole file is loaded.
+type JSONAdapter struct {
+	filePath      string
+	useFieldNames bool
+	autoSave      bool
+	// fieldNames holds the field names of the policy types, taken from the model of the construction
+	// or of the last load or save.
+	fieldNames map[string][]string
+	mutex      sync.Mutex
+}
+
+// jsonPolicy is the content of a JSON policy file, the rules by section and policy type.
+type jsonPolicy map[string]map[string][][]string
+
+// NewJSONAdapter is the constructor for JSONAdapter.
+// The field names of the rules written as objects are taken from the model when the policy is loaded or saved,
+// use NewJSONAdapterWithModel to change the policy with auto-save before.
+func NewJSONAdapter(filePath string) *JSONAdapter {
+	return &JSONAdapter{filePath: filePath}
+}
+
+// NewJSONAdapterWithModel is the constructor for JSONAdapter taking the field names of the policy definitions from m.
+func NewJSONAdapterWithModel(filePath string, m model.Model) *JSONAdapter {
+	a := &JSONAdapter{filePath: filePath}
+	a.setFieldNames(m)
+	return a
+}
+
+// UseFieldNames controls whether the rules of the policy definitions are written as objects
+// naming their values with the tokens of the definition, instead of arrays.
+func (a *JSONAdapter) UseFieldNames(useFieldNames bool) {
+	a.useFieldNames = useFieldNames
+}
+
+// EnableAutoSave controls whether the changes of the policy are applied to the file.
+// It is disabled by default, and the changes are kept in memory until SavePolicy is called.
+// When enabled, every change rewrites the file atomically under a lock shared with other processes.
+func (a *JSONAdapter) EnableAutoSave(autoSave bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.autoSave = autoSave
+}
+
+// setFieldNames takes the field names of the policy types from m.
+func (a *JSONAdapter) setFieldNames(m model.Model) {
+	a.fieldNames = make(map[string][]string)
+	for ptype, ast := range m["p"] {
+		names := make([]string, len(ast.Tokens))
+		for i, token := range ast.Tokens {
+			names[i] = strings.TrimPrefix(token, ptype+"_")
+		}
+		a.fieldNames[ptype] = names
+	}
+}
+
+// LoadPolicy loads all policy rules from the storage.
+func (a *JSONAdapter) LoadPolicy(m model.Model) error {
+	if a.filePath == "" {
+		return errors.New("invalid file path, file path cannot be empty")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.setFieldNames(m)
+	policy, err := a.readPolicyFile(false)
+	if err != nil {
+		return err
+	}
+	for _, sec := range []string{"p", "g"} {
+		for _, ptype := range sortedPolicyTypes(policy[sec]) {
+			for _, rule := range policy[sec][ptype] {
+				if err := persist.LoadPolicyArray(append([]string{ptype}, rule...), m); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SavePolicy saves all policy rules to the storage.
+func (a *JSONAdapter) SavePolicy(m model.Model) error {
+	if a.filePath == "" {
+		return errors.New("invalid file path, file path cannot be empty")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.setFieldNames(m)
+	policy := make(jsonPolicy)
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			if len(ast.Policy) == 0 {
+				continue
+			}
+			if policy[sec] == nil {
+				policy[sec] = make(map[string][][]string)
+			}
+			policy[sec][ptype] = ast.Policy
+		}
+	}
+
+	unlock, err := lockFile(a.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return a.writePolicyFile(policy)
+}
+
+// AddPolicy adds a policy rule to the storage.
+func (a *JSONAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return a.AddPolicies(sec, ptype, [][]string{rule})
+}
+
+// AddPolicies adds policy rules to the storage.
+func (a *JSONAdapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	return a.updatePolicyFile(sec, ptype, func(current [][]string) ([][]string, error) {
+		return append(current, rules...), nil
+	})
+}
+
+// RemovePolicy removes a policy rule from the storage.
+func (a *JSONAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.RemovePolicies(sec, ptype, [][]string{rule})
+}
+
+// RemovePolicies removes policy rules from the storage.
+func (a *JSONAdapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	return a.updatePolicyFile(sec, ptype, func(current [][]string) ([][]string, error) {
+		var kept [][]string
+		for _, rule := range current {
+			if !containsRule(rules, rule) {
+				kept = append(kept, rule)
+			}
+		}
+		return kept, nil
+	})
+}
+
+// RemoveFilteredPolicy removes policy rules that match the filter from the storage.
+func (a *JSONAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.updatePolicyFile(sec, ptype, func(current [][]string) ([][]string, error) {
+		var kept [][]string
+		for _, rule := range current {
+			if !ruleMatchesFilter(rule, fieldIndex, fieldValues) {
+				kept = append(kept, rule)
+			}
+		}
+		return kept, nil
+	})
+}
+
+// UpdatePolicy updates a policy rule of the storage, in place.
+func (a *JSONAdapter) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return a.UpdatePolicies(sec, ptype, [][]string{oldRule}, [][]string{newRule})
+}
+
+// UpdatePolicies updates policy rules of the storage, in place.
+// The file is left untouched if an old rule is not in it.
+func (a *JSONAdapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	if len(oldRules) != len(newRules) {
+		return errors.New("the number of old rules and new rules should be the same")
+	}
+	return a.updatePolicyFile(sec, ptype, func(current [][]string) ([][]string, error) {
+		for i, oldRule := range oldRules {
+			updated := false
+			for j, rule := range current {
+				if util.ArrayEquals(rule, oldRule) {
+					current[j] = newRules[i]
+					updated = true
+					break
+				}
+			}
+			if !updated {
+				return nil, fmt.Errorf("the rule %v of %s is not in the policy file", oldRule, ptype)
+			}
+		}
+		return current, nil
+	})
+}
+
+// UpdateFilteredPolicies deletes the policy rules that match the filter and adds the new rules
+// in place of the first of them, it returns the deleted rules.
+func (a *JSONAdapter) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	var oldRules [][]string
+	err := a.updatePolicyFile(sec, ptype, func(current [][]string) ([][]string, error) {
+		var kept [][]string
+		position := -1
+		for _, rule := range current {
+			if ruleMatchesFilter(rule, fieldIndex, fieldValues) {
+				if position == -1 {
+					position = len(kept)
+				}
+				oldRules = append(oldRules, rule)
+				continue
+			}
+			kept = append(kept, rule)
+		}
+		if position == -1 {
+			position = len(kept)
+		}
+		return append(kept[:position], append(append([][]string(nil), newRules...), kept[position:]...)...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oldRules, nil
+}
+
+// updatePolicyFile replaces the rules of ptype in the policy file with the rules returned by update, under the file lock.
+// It fails with "not implemented" when auto-save is disabled.
+func (a *JSONAdapter) updatePolicyFile(sec string, ptype string, update func(rules [][]string) ([][]string, error)) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.autoSave {
+		return errors.New("not implemented")
+	}
+	if a.filePath == "" {
+		return errors.New("invalid file path, file path cannot be empty")
+	}
+
+	unlock, err := lockFile(a.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	policy, err := a.readPolicyFile(true)
+	if err != nil {
+		return err
+	}
+	rules, err := update(policy[sec][ptype])
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		delete(policy[sec], ptype)
+		if len(policy[sec]) == 0 {
+			delete(policy, sec)
+		}
+	} else {
+		if policy[sec] == nil {
+			policy[sec] = make(map[string][][]string)
+		}
+		policy[sec][ptype] = rules
+	}
+	return a.writePolicyFile(policy)
+}
+
+// readPolicyFile reads the policy file, a missing file is an empty policy if allowMissing is true.
+func (a *JSONAdapter) readPolicyFile(allowMissing bool) (jsonPolicy, error) {
+	data, err := os.ReadFile(a.filePath)
+	if err != nil {
+		if allowMissing && os.IsNotExist(err) {
+			return make(jsonPolicy), nil
+		}
+		return nil, err
+	}
+	return a.decodePolicy(data)
+}
+
+func (a *JSONAdapter) decodePolicy(data []byte) (jsonPolicy, error) {
+	policy := make(jsonPolicy)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return policy, nil
+	}
+
+	var raw map[string]map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON policy file %s: %w", a.filePath, err)
+	}
+	for sec, ptypes := range raw {
+		if sec != "p" && sec != "g" {
+			return nil, fmt.Errorf("invalid JSON policy file %s: unknown section %q", a.filePath, sec)
+		}
+		policy[sec] = make(map[string][][]string)
+		for ptype, rules := range ptypes {
+			if !strings.HasPrefix(ptype, sec) {
+				return nil, fmt.Errorf("invalid JSON policy file %s: policy type %q in section %q", a.filePath, ptype, sec)
+			}
+			for i, data := range rules {
+				rule, err := a.decodeRule(ptype, data)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSON policy file %s: rule %d of %s: %w", a.filePath, i, ptype, err)
+				}
+				policy[sec][ptype] = append(policy[sec][ptype], rule)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// decodeRule decodes a rule written as an array of values or as an object naming its values.
+// The missing trailing fields of an object are left out, so that they get their default values.
+func (a *JSONAdapter) decodeRule(ptype string, data json.RawMessage) ([]string, error) {
+	var rule []string
+	if err := json.Unmarshal(data, &rule); err == nil {
+		return rule, nil
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.New("a rule should be an array or an object of strings")
+	}
+	names, ok := a.fieldNames[ptype]
+	if !ok {
+		return nil, unknownFieldNamesError(ptype)
+	}
+
+	length := 0
+	for name := range fields {
+		index := indexOf(names, name)
+		if index == -1 {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		if index+1 > length {
+			length = index + 1
+		}
+	}
+	rule = make([]string, length)
+	for i := range rule {
+		value, ok := fields[names[i]]
+		if !ok {
+			return nil, fmt.Errorf("missing field %q", names[i])
+		}
+		rule[i] = value
+	}
+	return rule, nil
+}
+
+// writePolicyFile writes policy to the policy file atomically, the caller holds the file lock.
+func (a *JSONAdapter) writePolicyFile(policy jsonPolicy) error {
+	encoded := make(map[string]map[string][]interface{})
+	for sec, ptypes := range policy {
+		encoded[sec] = make(map[string][]interface{})
+		for ptype, rules := range ptypes {
+			names, ok := a.fieldNames[ptype]
+			if a.useFieldNames && sec == "p" && !ok {
+				return unknownFieldNamesError(ptype)
+			}
+			values := make([]interface{}, len(rules))
+			for i, rule := range rules {
+				if a.useFieldNames && sec == "p" && len(rule) <= len(names) {
+					values[i] = namedRule{names: names, rule: rule}
+				} else {
+					values[i] = rule
+				}
+			}
+			encoded[sec][ptype] = values
+		}
+	}
+
+	data, err := json.MarshalIndent(encoded, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(a.filePath, append(data, '\n'))
+}
+
+func unknownFieldNamesError(ptype string) error {
+	return fmt.Errorf("the field names of %s are unknown, load the policy first or create the adapter with NewJSONAdapterWithModel", ptype)
+}
+
+// namedRule is a rule written as an object, with its fields in the order of the policy definition.
+type namedRule struct {
+	names []string
+	rule  []string
+}
+
+func (r namedRule) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, value := range r.rule {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(r.names[i])
+		buf.Write(name)
+		buf.WriteByte(':')
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encoded)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func sortedPolicyTypes(rules map[string][][]string) []string {
+	ptypes := make([]string, 0, len(rules))
+	for ptype := range rules {
+		ptypes = append(ptypes, ptype)
+	}
+	sort.Strings(ptypes)
+	return ptypes
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsRule(rules [][]string, rule []string) bool {
+	for _, r := range rules {
+		if util.ArrayEquals(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleMatchesFilter returns whether rule has the fieldValues from fieldIndex, an empty value matches any value.
+func ruleMatchesFilter(rule []string, fieldIndex int, fieldValues []string) bool {
+	for i, fieldValue := range fieldValues {
+		if fieldValue == "" {
+			continue
+		}
+		if fieldIndex+i >= len(rule) || rule[fieldIndex+i] != fieldValue {
+			return false
+		}
+	}
+	return true
+}
+
+// ConvertCSVToJSON converts the CSV policy file csvPath of the model m to the JSON policy file jsonPath.
+// With useFieldNames, the rules of the policy definitions are written as objects, see JSONAdapter.UseFieldNames.
+func ConvertCSVToJSON(m model.Model, csvPath string, jsonPath string, useFieldNames bool) error {
+	policy := m.Copy()
+	policy.ClearPolicy()
+	if err := NewAdapter(csvPath).LoadPolicy(policy); err != nil {
+		return err
+	}
+
+	a := NewJSONAdapter(jsonPath)
+	a.UseFieldNames(useFieldNames)
+	return a.SavePolicy(policy)
+}
+
+// ConvertJSONToCSV converts the JSON policy file jsonPath of the model m to the CSV policy file csvPath.
+func ConvertJSONToCSV(m model.Model, jsonPath string, csvPath string) error {
+	policy := m.Copy()
+	policy.ClearPolicy()
+	if err := NewJSONAdapter(jsonPath).LoadPolicy(policy); err != nil {
+		return err
+	}
+	return NewAdapter(csvPath).SavePolicy(policy)
+}
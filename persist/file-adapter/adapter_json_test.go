@@ -0,0 +1,227 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileadapter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3"
+	"github.com/casbin/casbin/v3/model"
+	fileadapter "github.com/casbin/casbin/v3/persist/file-adapter"
+	"github.com/casbin/casbin/v3/util"
+)
+
+func TestJSONAdapter(t *testing.T) {
+	e, err := casbin.NewEnforcer("../../examples/rbac_model.conf", fileadapter.NewJSONAdapter("../../examples/rbac_policy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv, _ := casbin.NewEnforcer("../../examples/rbac_model.conf", "../../examples/rbac_policy.csv")
+	for _, sec := range []string{"p", "g"} {
+		rules, _ := e.GetModel().GetPolicy(sec, sec)
+		expected, _ := csv.GetModel().GetPolicy(sec, sec)
+		if !util.Array2DEquals(rules, expected) {
+			t.Errorf("%s rules = %v, supposed to be %v", sec, rules, expected)
+		}
+	}
+	if ok, _ := e.Enforce("alice", "data2", "write"); !ok {
+		t.Error("alice should write data2")
+	}
+}
+
+func TestJSONAdapterAutoSave(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := fileadapter.NewJSONAdapter(path)
+	a.UseFieldNames(true)
+	e, err := casbin.NewEnforcer("../../examples/rbac_model.conf", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Auto-save is disabled by default.
+	if err := a.AddPolicy("p", "p", []string{"eve", "data3", "read"}); err == nil || err.Error() != "not implemented" {
+		t.Errorf("AddPolicy() = %v, supposed to be not implemented", err)
+	}
+	testPolicyFile(t, path, "{}")
+
+	a.EnableAutoSave(true)
+	if _, err := e.AddPolicies([][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"eve", "data3", "read"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.AddGroupingPolicy("alice", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.UpdatePolicy([]string{"bob", "data2", "write"}, []string{"bob", "data2", "read"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.RemoveFilteredPolicy(0, "eve"); err != nil {
+		t.Fatal(err)
+	}
+	testPolicyFile(t, path, `{
+  "g": {
+    "g": [
+      [
+        "alice",
+        "admin"
+      ]
+    ]
+  },
+  "p": {
+    "p": [
+      {
+        "sub": "alice",
+        "obj": "data1",
+        "act": "read"
+      },
+      {
+        "sub": "bob",
+        "obj": "data2",
+        "act": "read"
+      }
+    ]
+  }
+}
+`)
+
+	if _, err := e.RemovePolicy("alice", "data1", "read"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.UpdateFilteredPolicies([][]string{{"bob", "data3", "write"}}, 0, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file and the memory hold the same policy.
+	policy, _ := e.GetPolicy()
+	if err := e.LoadPolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := e.GetPolicy(); !util.Array2DEquals(reloaded, policy) || len(reloaded) != 1 {
+		t.Errorf("reloaded policy = %v, supposed to be %v", reloaded, policy)
+	}
+}
+
+func TestJSONAdapterFieldNames(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+
+	// The missing trailing fields of an object get their default values.
+	if err := os.WriteFile(path, []byte(`{"p": {"p": [{"sub": "alice", "obj": "data1", "act": "read"}]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	e, err := casbin.NewEnforcer("../../examples/rbac_with_optional_fields_model.conf", fileadapter.NewJSONAdapter(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := e.HasPolicy("alice", "data1", "read", "allow", "0"); !ok {
+		t.Error("the optional fields should get their default values")
+	}
+
+	for content, message := range map[string]string{
+		`{"p": {"p": [{"sub": "alice", "object": "data1"}]}}`: `unknown field "object"`,
+		`{"p": {"p": [{"sub": "alice", "act": "read"}]}}`:     `missing field "obj"`,
+		`{"p": {"p": [[1, 2, 3]]}}`:                           "an array or an object of strings",
+		`{"r": {"r": [["alice"]]}}`:                           `unknown section "r"`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.LoadPolicy(); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("LoadPolicy() error = %v, supposed to contain %s", err, message)
+		}
+	}
+}
+
+func TestJSONAdapterAutoSaveBeforeLoad(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+
+	// The field names are unknown until the policy is loaded or saved.
+	a := fileadapter.NewJSONAdapter(path)
+	a.UseFieldNames(true)
+	a.EnableAutoSave(true)
+	if err := a.AddPolicy("p", "p", []string{"alice", "data1", "read"}); err == nil || !strings.Contains(err.Error(), "field names of p are unknown") {
+		t.Errorf("AddPolicy() error = %v, supposed to report the unknown field names", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the policy file should not be written, got %v", err)
+	}
+
+	m, err := model.NewModelFromFile("../../examples/rbac_model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a = fileadapter.NewJSONAdapterWithModel(path, m)
+	a.UseFieldNames(true)
+	a.EnableAutoSave(true)
+	if err = a.AddPolicy("p", "p", []string{"alice", "data1", "read"}); err != nil {
+		t.Fatal(err)
+	}
+	testPolicyFile(t, path, `{
+  "p": {
+    "p": [
+      {
+        "sub": "alice",
+        "obj": "data1",
+        "act": "read"
+      }
+    ]
+  }
+}
+`)
+
+	// A rule missing from the file is not updated.
+	if err = a.UpdatePolicy("p", "p", []string{"bob", "data2", "write"}, []string{"bob", "data2", "read"}); err == nil {
+		t.Error("UpdatePolicy() should fail when the old rule is not in the file")
+	}
+}
+
+func TestConvertPolicyFile(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	jsonPath := filepath.Join(dir, "policy.json")
+	csvPath := filepath.Join(dir, "policy.csv")
+
+	m, err := model.NewModelFromFile("../../examples/rbac_with_domains_model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fileadapter.ConvertCSVToJSON(m, "../../examples/rbac_with_domains_policy.csv", jsonPath, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileadapter.ConvertJSONToCSV(m, jsonPath, csvPath); err != nil {
+		t.Fatal(err)
+	}
+
+	e, _ := casbin.NewEnforcer(m, fileadapter.NewAdapter("../../examples/rbac_with_domains_policy.csv"))
+	converted, _ := casbin.NewEnforcer(m, fileadapter.NewAdapter(csvPath))
+	for _, sec := range []string{"p", "g"} {
+		rules, _ := converted.GetModel().GetPolicy(sec, sec)
+		expected, _ := e.GetModel().GetPolicy(sec, sec)
+		if !util.Array2DEquals(rules, expected) {
+			t.Errorf("converted %s rules = %v, supposed to be %v", sec, rules, expected)
+		}
+	}
+}
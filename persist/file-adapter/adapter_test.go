@@ -0,0 +1,275 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileadapter_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/casbin/casbin/v3"
+	"github.com/casbin/casbin/v3/persist"
+	"github.com/casbin/casbin/v3/persist/adaptertest"
+	fileadapter "github.com/casbin/casbin/v3/persist/file-adapter"
+	"github.com/casbin/casbin/v3/util"
+)
+
+const autoSavePolicy = `# the policy of data1
+p, alice, data1, read
+
+# the policy of data2
+p, bob, data2, write
+p, data2_admin, data2, read
+p, data2_admin, data2, write
+g, alice, data2_admin
+`
+
+// newAutoSaveEnforcer returns an enforcer auto-saving to a copy of autoSavePolicy in dir.
+func newAutoSaveEnforcer(t *testing.T, dir string) (*casbin.Enforcer, string) {
+	t.Helper()
+
+	path := filepath.Join(dir, "policy.csv")
+	if err := os.WriteFile(path, []byte(autoSavePolicy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := fileadapter.NewAdapter(path)
+	a.EnableAutoSave(true)
+	e, err := casbin.NewEnforcer("../../examples/rbac_model.conf", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e, path
+}
+
+func testTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "casbin-file-adapter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func testPolicyFile(t *testing.T, path string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expected {
+		t.Errorf("policy file:\n%s\nsupposed to be:\n%s", data, expected)
+	}
+}
+
+func TestAutoSaveDisabled(t *testing.T) {
+	a := fileadapter.NewAdapter("../../examples/rbac_policy.csv")
+	if err := a.AddPolicy("p", "p", []string{"eve", "data3", "read"}); err == nil || err.Error() != "not implemented" {
+		t.Errorf("AddPolicy() = %v, supposed to be not implemented", err)
+	}
+}
+
+func TestAutoSave(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	e, path := newAutoSaveEnforcer(t, dir)
+
+	if _, err := e.AddPolicies([][]string{{"eve", "data3", "read"}, {"eve", "data3", "write"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.RemovePolicy("bob", "data2", "write"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.UpdatePolicy([]string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.RemoveGroupingPolicy("alice", "data2_admin"); err != nil {
+		t.Fatal(err)
+	}
+	testPolicyFile(t, path, `# the policy of data1
+p, alice, data1, write
+
+# the policy of data2
+p, data2_admin, data2, read
+p, data2_admin, data2, write
+p, eve, data3, read
+p, eve, data3, write
+`)
+
+	if _, err := e.RemoveFilteredPolicy(0, "eve"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.UpdateFilteredPolicies([][]string{{"data2_admin", "data2", "delete"}}, 0, "data2_admin"); err != nil {
+		t.Fatal(err)
+	}
+	testPolicyFile(t, path, `# the policy of data1
+p, alice, data1, write
+
+# the policy of data2
+p, data2_admin, data2, delete
+`)
+
+	// The file and the memory hold the same policy.
+	policy, _ := e.GetPolicy()
+	if err := e.LoadPolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := e.GetPolicy(); !util.Array2DEquals(policy, reloaded) {
+		t.Errorf("reloaded policy = %v, supposed to be %v", reloaded, policy)
+	}
+}
+
+func TestUpdateMissingPolicy(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	e, path := newAutoSaveEnforcer(t, dir)
+
+	a := e.GetAdapter().(*fileadapter.Adapter)
+	err := a.UpdatePolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"eve", "data3", "read"}},
+		[][]string{{"alice", "data1", "write"}, {"eve", "data3", "write"}})
+	if err == nil {
+		t.Error("UpdatePolicies() should fail when an old rule is not in the file")
+	}
+	testPolicyFile(t, path, autoSavePolicy)
+}
+
+func TestAutoSaveConcurrent(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	_, path := newAutoSaveEnforcer(t, dir)
+
+	// Adapters sharing the file do not lose each other's changes.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a := fileadapter.NewAdapter(path)
+			a.EnableAutoSave(true)
+			if err := a.AddPolicy("p", "p", []string{fmt.Sprintf("user%d", i), "data3", "read"}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if !strings.Contains(string(data), fmt.Sprintf("p, user%d, data3, read\n", i)) {
+			t.Errorf("the rule of user%d is lost", i)
+		}
+	}
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("temporary files are left: %v", matches)
+	}
+}
+
+func TestQuotedPolicyFile(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.csv")
+	policy := `# eval rules
+p, "r.sub.Age > 18 && r.sub.Name != ""eve, the admin""", /data1, read
+p, "r.sub.Age < 60 &&
+r.sub.Age > 10", /data2, write
+`
+	if err := os.WriteFile(path, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := fileadapter.NewAdapter(path)
+	a.EnableAutoSave(true)
+	e, err := casbin.NewEnforcer("../../examples/abac_rule_model.conf", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := e.HasPolicy("r.sub.Age < 60 &&\nr.sub.Age > 10", "/data2", "write"); !ok {
+		t.Error("a quoted line break should be kept in the rule")
+	}
+
+	if _, err := e.AddPolicy(`r.sub.Name in ("alice", "bob")`, "/data3", "read"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.RemovePolicy("r.sub.Age < 60 &&\nr.sub.Age > 10", "/data2", "write"); err != nil {
+		t.Fatal(err)
+	}
+	testPolicyFile(t, path, `# eval rules
+p, "r.sub.Age > 18 && r.sub.Name != ""eve, the admin""", /data1, read
+p, "r.sub.Name in (""alice"", ""bob"")", /data3, read
+`)
+
+	// A saved policy is loaded back as is.
+	rules, _ := e.GetPolicy()
+	if err := e.SavePolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.LoadPolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := e.GetPolicy(); !util.Array2DEquals(reloaded, rules) {
+		t.Errorf("reloaded policy = %q, supposed to be %q", reloaded, rules)
+	}
+}
+
+func TestAdapterConformance(t *testing.T) {
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	count := 0
+	newPath := func(name string) string {
+		count++
+		return filepath.Join(dir, fmt.Sprintf("%d_%s", count, name))
+	}
+	filter := func(pFields []string, gFields []string) interface{} {
+		return &fileadapter.Filter{P: pFields, G: gFields}
+	}
+
+	t.Run("Adapter", func(t *testing.T) {
+		adaptertest.Run(t, adaptertest.Config{
+			NewAdapter: func(t *testing.T) persist.Adapter {
+				a := fileadapter.NewAdapter(newPath("policy.csv"))
+				a.EnableAutoSave(true)
+				return a
+			},
+		})
+	})
+	t.Run("FilteredAdapter", func(t *testing.T) {
+		adaptertest.Run(t, adaptertest.Config{
+			NewAdapter: func(t *testing.T) persist.Adapter {
+				path := newPath("policy.csv")
+				if err := os.WriteFile(path, nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+				a := fileadapter.NewFilteredAdapter(path)
+				a.EnableAutoSave(true)
+				return a
+			},
+			Filter: filter,
+		})
+	})
+	t.Run("JSONAdapter", func(t *testing.T) {
+		adaptertest.Run(t, adaptertest.Config{
+			NewAdapter: func(t *testing.T) persist.Adapter {
+				a := fileadapter.NewJSONAdapter(newPath("policy.json"))
+				a.EnableAutoSave(true)
+				return a
+			},
+		})
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package fileadapter
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	// lockTimeout is how long a write waits for the lock before failing.
+	lockTimeout = 30 * time.Second
+	// lockStaleAge is the age of a lock file left behind by a crashed writer. A write holds the lock
+	// for the time of a rewrite of the file, so an older lock file is taken over.
+	lockStaleAge = 10 * time.Second
+)
+
+// lockCount makes the owner tokens of the locks taken by this process unique.
+var lockCount uint64
+
+// lockFile takes an exclusive lock shared with other processes by creating the lock file of path,
+// the lock is released by removing it. The lock file holds a token of its owner, so a lock file
+// older than lockStaleAge is taken over without removing the lock of another writer,
+// and an error is returned if the lock cannot be taken within lockTimeout.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	token := fmt.Sprintf("%d-%d-%d", os.Getpid(), time.Now().UnixNano(), atomic.AddUint64(&lockCount, 1))
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		owned, err := createLockFile(lockPath, token)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			return func() {
+				if ownsLockFile(lockPath, token) {
+					_ = os.Remove(lockPath)
+				}
+			}, nil
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStaleAge {
+			takeOverLockFile(lockPath, token)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for the lock file %s", lockTimeout, lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// createLockFile creates the lock file holding token, it returns false if the lock file exists
+// or has been taken over by another writer since it was created.
+func createLockFile(lockPath string, token string) (bool, error) {
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = f.WriteString(token)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(lockPath)
+		return false, err
+	}
+	return ownsLockFile(lockPath, token), nil
+}
+
+// ownsLockFile returns whether the lock file holds token.
+func ownsLockFile(lockPath string, token string) bool {
+	content, err := os.ReadFile(lockPath)
+	return err == nil && string(content) == token
+}
+
+// takeOverLockFile removes a stale lock file by renaming it to a name unique to token first,
+// so that only one writer gets it. A lock file found fresh once renamed has been taken over
+// by another writer in the meantime, it is given back to its owner unless the lock was taken again.
+func takeOverLockFile(lockPath string, token string) {
+	stalePath := lockPath + "." + token
+	if err := os.Rename(lockPath, stalePath); err != nil {
+		return
+	}
+	defer os.Remove(stalePath)
+
+	info, err := os.Stat(stalePath)
+	if err != nil || time.Since(info.ModTime()) > lockStaleAge {
+		return
+	}
+	owner, err := os.ReadFile(stalePath)
+	if err != nil {
+		return
+	}
+	_, _ = createLockFile(lockPath, string(owner))
+}
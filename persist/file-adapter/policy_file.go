@@ -0,0 +1,198 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileadapter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/casbin/casbin/v3/persist"
+	"github.com/casbin/casbin/v3/util"
+)
+
+// policyLine is a line of a policy file, or several lines for a record with a quoted line break.
+// tokens holds the policy type and the rule of the line, and is nil for an empty line or a comment.
+type policyLine struct {
+	text   string
+	tokens []string
+}
+
+func newPolicyLine(ptype string, rule []string) *policyLine {
+	tokens := append([]string{ptype}, rule...)
+	return &policyLine{
+		text:   persist.FormatPolicyRecord(tokens),
+		tokens: tokens,
+	}
+}
+
+// parsePolicyLine parses a line of a policy file the same way as persist.LoadPolicyLine.
+func parsePolicyLine(text string) *policyLine {
+	line := &policyLine{text: text}
+	if tokens, err := persist.NewPolicyReader(strings.NewReader(text)).Read(); err == nil {
+		line.tokens = tokens
+	}
+	return line
+}
+
+// splitPolicyLines splits the text of a policy file into lines, keeping a quoted line break in its line.
+func splitPolicyLines(text string) []*policyLine {
+	var lines []*policyLine
+	var record []string
+	for _, s := range strings.Split(text, "\n") {
+		record = append(record, strings.TrimSuffix(s, "\r"))
+		if inQuotedField(strings.Join(record, "\n")) {
+			continue
+		}
+		lines = append(lines, parsePolicyLine(strings.Join(record, "\n")))
+		record = nil
+	}
+	if record != nil {
+		lines = append(lines, parsePolicyLine(strings.Join(record, "\n")))
+	}
+	return lines
+}
+
+// inQuotedField returns whether text ends inside a quoted field of a record.
+func inQuotedField(text string) bool {
+	if trimmed := strings.TrimSpace(text); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return false
+	}
+	quoted := false
+	fieldStart := true
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quoted:
+			if c == '"' {
+				if i+1 < len(text) && text[i+1] == '"' {
+					i++
+				} else {
+					quoted = false
+				}
+			}
+		case c == ',':
+			fieldStart = true
+		case c == ' ' || c == '\t':
+		case c == '"' && fieldStart:
+			quoted = true
+			fieldStart = false
+		default:
+			fieldStart = false
+		}
+	}
+	return quoted
+}
+
+func (line *policyLine) matches(ptype string, rule []string) bool {
+	return len(line.tokens) == len(rule)+1 && line.tokens[0] == ptype && util.ArrayEquals(line.tokens[1:], rule)
+}
+
+func (line *policyLine) matchesAny(ptype string, rules [][]string) bool {
+	for _, rule := range rules {
+		if line.matches(ptype, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilter returns whether the line holds a rule of ptype matching the filter, see ruleMatchesFilter.
+func (line *policyLine) matchesFilter(ptype string, fieldIndex int, fieldValues []string) bool {
+	return len(line.tokens) != 0 && line.tokens[0] == ptype && ruleMatchesFilter(line.tokens[1:], fieldIndex, fieldValues)
+}
+
+// updatePolicyFile rewrites the policy file with the lines returned by update, under the file lock.
+// The untouched lines are written back as they were, so comments and ordering are preserved.
+func (a *Adapter) updatePolicyFile(update func(lines []*policyLine) ([]*policyLine, error)) error {
+	if a.filePath == "" {
+		return errors.New("invalid file path, file path cannot be empty")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	unlock, err := lockFile(a.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(a.filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	text := string(data)
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+	}
+	trailingNewline := strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+
+	var lines []*policyLine
+	if text != "" {
+		lines = splitPolicyLines(text)
+	}
+
+	lines, err = update(lines)
+	if err != nil {
+		return err
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	text = strings.Join(texts, newline)
+	if trailingNewline && text != "" {
+		text += newline
+	}
+	return writeFileAtomic(a.filePath, []byte(text))
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it to path,
+// so that readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpPath, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
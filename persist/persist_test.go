@@ -15,11 +15,17 @@
 package persist_test
 
 import (
+	stderrors "errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/casbin/casbin/v3"
+	"github.com/casbin/casbin/v3/errors"
 	"github.com/casbin/casbin/v3/model"
 	"github.com/casbin/casbin/v3/persist"
+	stringadapter "github.com/casbin/casbin/v3/persist/string-adapter"
+	"github.com/casbin/casbin/v3/util"
 )
 
 func TestPersist(t *testing.T) {
@@ -51,3 +57,160 @@ func TestDuplicateRuleInAdapter(t *testing.T) {
 
 	testRuleCount(t, e.GetModel(), 1, "p", "p", "LoadPolicyArray")
 }
+
+func TestPolicyReader(t *testing.T) {
+	text := `# a comment
+p, alice, data1, read
+
+  # an indented comment
+p, "keyMatch(r.obj, p.obj)", "say ""hi""",  spaced  
+p, "a
+multi-line value", data2
+p, r.obj == "unquoted", data3,
+p, "bad" value, data4
+p, bob, data5, write
+`
+	r := persist.NewPolicyReader(strings.NewReader(text))
+	expected := [][]string{
+		{"p", "alice", "data1", "read"},
+		{"p", "keyMatch(r.obj, p.obj)", `say "hi"`, "spaced"},
+		{"p", "a\nmulti-line value", "data2"},
+		{"p", `r.obj == "unquoted"`, "data3", ""},
+	}
+	for _, want := range expected {
+		record, err := r.Read()
+		if err != nil || !util.ArrayEquals(record, want) {
+			t.Errorf("Read() = %q, %v, supposed to be %q", record, err, want)
+		}
+	}
+	// The reading goes on after a syntax error.
+	if _, err := r.Read(); err == nil || !strings.Contains(err.Error(), "line 9") {
+		t.Errorf("Read() error = %v, supposed to be at line 9", err)
+	}
+	if record, err := r.Read(); err != nil || !util.ArrayEquals(record, []string{"p", "bob", "data5", "write"}) {
+		t.Errorf("Read() = %q, %v", record, err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read() error = %v, supposed to be EOF", err)
+	}
+
+	if _, err := persist.ReadPolicyRecords("p, \"unterminated, data1\n"); err == nil {
+		t.Error("an unterminated quoted field should be an error")
+	}
+}
+
+func TestFormatPolicyRecord(t *testing.T) {
+	records := [][]string{
+		{"p", "alice", "data1", "read"},
+		{"p", "keyMatch(r.obj, p.obj)", `say "hi"`, " spaced "},
+		{"p", "a\nmulti-line value", "", "data2"},
+		{"g", "alice", "admin"},
+	}
+	text := ""
+	for _, record := range records {
+		text += persist.FormatPolicyRecord(record) + "\n"
+	}
+	if !strings.HasPrefix(text, "p, alice, data1, read\np, \"keyMatch(r.obj, p.obj)\", \"say \"\"hi\"\"\", \" spaced \"\n") {
+		t.Errorf("FormatPolicyRecord() gives:\n%s", text)
+	}
+
+	read, err := persist.ReadPolicyRecords(text)
+	if err != nil || !util.Array2DEquals(read, records) {
+		t.Errorf("ReadPolicyRecords() = %q, %v, supposed to be %q", read, err, records)
+	}
+}
+
+type testSubject struct {
+	Name string
+	Age  int
+}
+
+func TestEvalRuleRoundTrip(t *testing.T) {
+	a := stringadapter.NewAdapter(`p, "r.sub.Age > 18 && r.sub.Name != ""eve, the admin""", /data1, read
+p, r.sub.Age < 60, /data2, write`)
+	e, err := casbin.NewEnforcer("../examples/abac_rule_model.conf", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := e.Enforce(testSubject{"alice", 20}, "/data1", "read"); !ok {
+		t.Error("alice should read /data1")
+	}
+	if ok, _ := e.Enforce(testSubject{"eve, the admin", 20}, "/data1", "read"); ok {
+		t.Error("eve should not read /data1")
+	}
+
+	if _, err := e.AddPolicy(`r.sub.Name == "bob"`, "/data3", "read"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SavePolicy(); err != nil {
+		t.Fatal(err)
+	}
+	policy, _ := e.GetPolicy()
+	if err := e.LoadPolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := e.GetPolicy(); !util.Array2DEquals(reloaded, policy) {
+		t.Errorf("reloaded policy = %q, supposed to be %q", reloaded, policy)
+	}
+	if ok, _ := e.Enforce(testSubject{"bob", 70}, "/data3", "read"); !ok {
+		t.Error("bob should read /data3")
+	}
+}
+
+func TestWatcherMessage(t *testing.T) {
+	data, err := persist.EncodeWatcherMessage(persist.WatcherMessage{
+		Method:   persist.UpdateForUpdatePolicy,
+		Sec:      "p",
+		PType:    "p",
+		Rules:    [][]string{{"alice", "data1", "read"}},
+		NewRules: [][]string{{"alice", "data1", "write"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":1,"method":"UpdateForUpdatePolicy","sec":"p","ptype":"p","rules":[["alice","data1","read"]],"newRules":[["alice","data1","write"]]}`
+	if data != expected {
+		t.Errorf("EncodeWatcherMessage() = %s, supposed to be %s", data, expected)
+	}
+
+	message, err := persist.DecodeWatcherMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Version != persist.WatcherMessageVersion || message.Method != persist.UpdateForUpdatePolicy ||
+		!util.Array2DEquals(message.Rules, [][]string{{"alice", "data1", "read"}}) ||
+		!util.Array2DEquals(message.NewRules, [][]string{{"alice", "data1", "write"}}) {
+		t.Errorf("DecodeWatcherMessage() = %+v", message)
+	}
+
+	for _, data := range []string{
+		``,
+		`not a message`,
+		`{"version":1,"method":"UpdateForNothing"}`,
+		`{"version":1,"method":"UpdateForAddPolicy","sec":"p","ptype":"p"}`,
+		`{"version":1,"method":"UpdateForAddPolicy","rules":[["alice","data1","read"]]}`,
+		`{"version":1,"method":"UpdateForRemoveFilteredPolicy","sec":"p","ptype":"p","fieldIndex":1}`,
+		`{"version":1,"method":"UpdateForUpdatePolicies","sec":"p","ptype":"p","rules":[["alice","data1","read"]]}`,
+	} {
+		if _, err := persist.DecodeWatcherMessage(data); !stderrors.Is(err, errors.ErrInvalidWatcherMessage) {
+			t.Errorf("DecodeWatcherMessage(%q) returned %v, supposed to be an invalid watcher message", data, err)
+		}
+	}
+	for _, data := range []string{`{"method":"Update"}`, `{"version":2,"method":"Update"}`} {
+		if _, err := persist.DecodeWatcherMessage(data); !stderrors.Is(err, errors.ErrUnsupportedWatcherMessageVersion) {
+			t.Errorf("DecodeWatcherMessage(%q) returned %v, supposed to be an unsupported version", data, err)
+		}
+	}
+}
+
+func TestWatcherSequence(t *testing.T) {
+	var sequence persist.WatcherSequence
+	sequence.SetInstanceID("node1")
+	for i := uint64(1); i <= 3; i++ {
+		message := persist.WatcherMessage{Method: persist.Update}
+		sequence.Stamp(&message)
+		if message.Source != "node1" || message.Sequence != i {
+			t.Errorf("Stamp() = %s %d, supposed to be node1 %d", message.Source, message.Sequence, i)
+		}
+	}
+}
@@ -21,7 +21,6 @@ import (
 
 	"github.com/casbin/casbin/v3/model"
 	"github.com/casbin/casbin/v3/persist"
-	"github.com/casbin/casbin/v3/util"
 )
 
 // Adapter is the string adapter for Casbin.
@@ -42,14 +41,14 @@ func (a *Adapter) LoadPolicy(model model.Model) error {
 	if a.Line == "" {
 		return errors.New("invalid line, line cannot be empty")
 	}
-	strs := strings.Split(a.Line, "\n")
-	for _, str := range strs {
-		if str == "" {
-			continue
-		}
-		_ = persist.LoadPolicyLine(str, model)
+	// the line is parsed before loading any rule, so that a malformed line loads nothing
+	records, err := persist.ReadPolicyRecords(a.Line)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		_ = persist.LoadPolicyArray(record, model)
 	}
-
 	return nil
 }
 
@@ -58,16 +57,14 @@ func (a *Adapter) SavePolicy(model model.Model) error {
 	var tmp bytes.Buffer
 	for ptype, ast := range model["p"] {
 		for _, rule := range ast.Policy {
-			tmp.WriteString(ptype + ", ")
-			tmp.WriteString(util.ArrayToString(rule))
+			tmp.WriteString(persist.FormatPolicyRecord(append([]string{ptype}, rule...)))
 			tmp.WriteString("\n")
 		}
 	}
 
 	for ptype, ast := range model["g"] {
 		for _, rule := range ast.Policy {
-			tmp.WriteString(ptype + ", ")
-			tmp.WriteString(util.ArrayToString(rule))
+			tmp.WriteString(persist.FormatPolicyRecord(append([]string{ptype}, rule...)))
 			tmp.WriteString("\n")
 		}
 	}
@@ -99,3 +99,26 @@ g, alice, data_group_admin
 		t.Error("unexpected enforce result")
 	}
 }
+
+func Test_StringMalformed(t *testing.T) {
+	a := NewAdapter("p, alice, data1, read\np, \"unterminated, data2, read\n")
+	m, _ := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+	if err := a.LoadPolicy(m); err == nil {
+		t.Error("a malformed line should be an error")
+	}
+	if len(m["p"]["p"].Policy) != 0 {
+		t.Errorf("a malformed line should load no rule, got %v", m["p"]["p"].Policy)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/casbin/casbin/v3/errors"
+)
+
+// WatcherMessageVersion is the version of the format of the watcher messages written by EncodeWatcherMessage.
+const WatcherMessageVersion = 1
+
+// UpdateType is the type of a policy change notified by a watcher, named after the method of the watcher.
+type UpdateType string
+
+const (
+	Update                        UpdateType = "Update"
+	UpdateForAddPolicy            UpdateType = "UpdateForAddPolicy"
+	UpdateForRemovePolicy         UpdateType = "UpdateForRemovePolicy"
+	UpdateForRemoveFilteredPolicy UpdateType = "UpdateForRemoveFilteredPolicy"
+	UpdateForSavePolicy           UpdateType = "UpdateForSavePolicy"
+	UpdateForAddPolicies          UpdateType = "UpdateForAddPolicies"
+	UpdateForRemovePolicies       UpdateType = "UpdateForRemovePolicies"
+	UpdateForUpdatePolicy         UpdateType = "UpdateForUpdatePolicy"
+	UpdateForUpdatePolicies       UpdateType = "UpdateForUpdatePolicies"
+)
+
+// WatcherMessage is a policy change notified by a watcher, encoded in JSON by EncodeWatcherMessage, e.g.
+// {"version":1,"method":"UpdateForAddPolicy","sec":"p","ptype":"p","rules":[["alice","data1","read"]]}.
+type WatcherMessage struct {
+	Version     int        `json:"version"`
+	Method      UpdateType `json:"method"`
+	Sec         string     `json:"sec,omitempty"`
+	PType       string     `json:"ptype,omitempty"`
+	FieldIndex  int        `json:"fieldIndex,omitempty"`
+	FieldValues []string   `json:"fieldValues,omitempty"`
+	// Rules holds the added or removed rules, or the old rules of an update.
+	Rules [][]string `json:"rules,omitempty"`
+	// NewRules holds the new rules of an update.
+	NewRules [][]string `json:"newRules,omitempty"`
+	// Source is the instance ID of the enforcer notifying the change, see InstanceWatcher.
+	Source string `json:"source,omitempty"`
+	// Sequence is the number of the message among the messages of its source, starting at 1.
+	Sequence uint64 `json:"sequence,omitempty"`
+}
+
+// EncodeWatcherMessage returns the message in JSON with the current WatcherMessageVersion.
+func EncodeWatcherMessage(message WatcherMessage) (string, error) {
+	message.Version = WatcherMessageVersion
+	data, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DecodeWatcherMessage parses a message written by EncodeWatcherMessage.
+// It returns errors.ErrUnsupportedWatcherMessageVersion for a message of another version,
+// and errors.ErrInvalidWatcherMessage for a message of another format or missing the fields of its method.
+func DecodeWatcherMessage(data string) (*WatcherMessage, error) {
+	var message WatcherMessage
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidWatcherMessage, err.Error())
+	}
+	if message.Version != WatcherMessageVersion {
+		return nil, fmt.Errorf("%w: %d", errors.ErrUnsupportedWatcherMessageVersion, message.Version)
+	}
+
+	switch message.Method {
+	case Update, UpdateForSavePolicy:
+		return &message, nil
+	case UpdateForAddPolicy, UpdateForRemovePolicy, UpdateForAddPolicies, UpdateForRemovePolicies:
+		if len(message.Rules) == 0 {
+			return nil, fmt.Errorf("%w: %s without rules", errors.ErrInvalidWatcherMessage, message.Method)
+		}
+	case UpdateForRemoveFilteredPolicy:
+		if len(message.FieldValues) == 0 {
+			return nil, fmt.Errorf("%w: %s without field values", errors.ErrInvalidWatcherMessage, message.Method)
+		}
+	case UpdateForUpdatePolicy, UpdateForUpdatePolicies:
+		if len(message.Rules) != len(message.NewRules) {
+			return nil, fmt.Errorf("%w: %s with %d old rules and %d new rules", errors.ErrInvalidWatcherMessage, message.Method, len(message.Rules), len(message.NewRules))
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown method %q", errors.ErrInvalidWatcherMessage, message.Method)
+	}
+	if message.Sec == "" || message.PType == "" {
+		return nil, fmt.Errorf("%w: %s without sec or ptype", errors.ErrInvalidWatcherMessage, message.Method)
+	}
+	return &message, nil
+}
+
+// InstanceWatcher is a watcher stamping its messages with the instance ID of its enforcer
+// and a monotonic sequence number, e.g. with a WatcherSequence,
+// so that an enforcer ignores its own messages and reloads its policy when it misses a message.
+type InstanceWatcher interface {
+	Watcher
+	// SetInstanceID sets the instance ID of the enforcer of the watcher, it is called by Enforcer.SetWatcher.
+	SetInstanceID(id string)
+}
+
+// WatcherSequence stamps the messages of an InstanceWatcher with the instance ID and the next sequence number.
+type WatcherSequence struct {
+	mutex    sync.Mutex
+	id       string
+	sequence uint64
+}
+
+// SetInstanceID sets the instance ID of the messages.
+func (s *WatcherSequence) SetInstanceID(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.id = id
+}
+
+// Stamp sets the source and the next sequence number of message.
+// The messages should be sent in the order of their stamps.
+func (s *WatcherSequence) Stamp(message *WatcherMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sequence++
+	message.Source = s.id
+	message.Sequence = s.sequence
+}
@@ -0,0 +1,446 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watchertest provides conformance tests of the persist watchers, e.g.
+//
+//	func TestWatcher(t *testing.T) {
+//		watchertest.Run(t, watchertest.Config{
+//			NewWatchers: func(t *testing.T) (persist.Watcher, persist.Watcher) {
+//				return mywatcher.NewWatcher(testAddress), mywatcher.NewWatcher(testAddress)
+//			},
+//		})
+//	}
+//
+// The tests check that each policy change of an enforcer calls the update method of its watcher with the changed rules,
+// e.g. Enforcer.AddPolicy calls WatcherEx.UpdateForAddPolicy, and that the other watcher receives a message.
+// The messages of a persist.InstanceWatcher should be stamped with the instance ID of the enforcer, in sequence.
+package watchertest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v3"
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/persist"
+	fileadapter "github.com/casbin/casbin/v3/persist/file-adapter"
+)
+
+// Config is the configuration of the conformance tests of a watcher.
+type Config struct {
+	// NewWatchers creates the watchers of two instances, a change notified by a watcher should be received by the other.
+	NewWatchers func(t *testing.T) (persist.Watcher, persist.Watcher)
+	// Timeout is the time to wait for a message, one second by default.
+	Timeout time.Duration
+}
+
+// mutation is a policy change of an enforcer.
+type mutation struct {
+	name  string
+	apply func(e *casbin.Enforcer) (bool, error)
+	// call is the expected call of the watcher if it implements persist.WatcherEx,
+	// or persist.UpdatableWatcher if updatable is true.
+	call      string
+	updatable bool
+}
+
+// mutations are applied in order to the policy of examples/rbac_model.conf and examples/rbac_policy.csv.
+var mutations = []mutation{
+	{
+		name:  "AddPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.AddPolicy("eve", "data3", "read") },
+		call:  formatCall("UpdateForAddPolicy", "p", "p", []string{"eve", "data3", "read"}),
+	},
+	{
+		name:  "AddNamedPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.AddNamedPolicy("p", "eve", "data6", "read") },
+		call:  formatCall("UpdateForAddPolicy", "p", "p", []string{"eve", "data6", "read"}),
+	},
+	{
+		name: "AddPolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.AddPolicies([][]string{{"eve", "data3", "write"}, {"eve", "data4", "read"}})
+		},
+		call: formatCall("UpdateForAddPolicies", "p", "p", [][]string{{"eve", "data3", "write"}, {"eve", "data4", "read"}}),
+	},
+	{
+		name:  "AddPoliciesEx",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.AddPoliciesEx([][]string{{"eve", "data4", "write"}}) },
+		call:  formatCall("UpdateForAddPolicies", "p", "p", [][]string{{"eve", "data4", "write"}}),
+	},
+	{
+		name:  "RemovePolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.RemovePolicy("eve", "data3", "read") },
+		call:  formatCall("UpdateForRemovePolicy", "p", "p", []string{"eve", "data3", "read"}),
+	},
+	{
+		name:  "RemovePolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.RemovePolicies([][]string{{"eve", "data3", "write"}}) },
+		call:  formatCall("UpdateForRemovePolicies", "p", "p", [][]string{{"eve", "data3", "write"}}),
+	},
+	{
+		name:  "RemoveFilteredPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.RemoveFilteredPolicy(1, "data4") },
+		call:  formatCall("UpdateForRemoveFilteredPolicy", "p", "p", 1, []string{"data4"}),
+	},
+	{
+		name: "UpdatePolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.UpdatePolicy([]string{"alice", "data1", "read"}, []string{"alice", "data1", "write"})
+		},
+		call:      formatCall("UpdateForUpdatePolicy", "p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}),
+		updatable: true,
+	},
+	{
+		name: "UpdatePolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.UpdatePolicies([][]string{{"bob", "data2", "write"}}, [][]string{{"bob", "data2", "read"}})
+		},
+		call:      formatCall("UpdateForUpdatePolicies", "p", "p", [][]string{{"bob", "data2", "write"}}, [][]string{{"bob", "data2", "read"}}),
+		updatable: true,
+	},
+	{
+		name: "UpdateFilteredPolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.UpdateFilteredPolicies([][]string{{"data2_admin", "data3", "read"}}, 0, "data2_admin", "data2", "read")
+		},
+		call:      formatCall("UpdateForUpdatePolicies", "p", "p", [][]string{{"data2_admin", "data2", "read"}}, [][]string{{"data2_admin", "data3", "read"}}),
+		updatable: true,
+	},
+	{
+		name:  "AddGroupingPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.AddGroupingPolicy("eve", "data2_admin") },
+		call:  formatCall("UpdateForAddPolicy", "g", "g", []string{"eve", "data2_admin"}),
+	},
+	{
+		name:  "AddNamedGroupingPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.AddNamedGroupingPolicy("g", "frank", "data2_admin") },
+		call:  formatCall("UpdateForAddPolicy", "g", "g", []string{"frank", "data2_admin"}),
+	},
+	{
+		name: "AddGroupingPolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.AddGroupingPolicies([][]string{{"bob", "data2_admin"}})
+		},
+		call: formatCall("UpdateForAddPolicies", "g", "g", [][]string{{"bob", "data2_admin"}}),
+	},
+	{
+		name: "UpdateGroupingPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.UpdateGroupingPolicy([]string{"eve", "data2_admin"}, []string{"eve", "admin"})
+		},
+		call:      formatCall("UpdateForUpdatePolicy", "g", "g", []string{"eve", "data2_admin"}, []string{"eve", "admin"}),
+		updatable: true,
+	},
+	{
+		name: "UpdateFilteredGroupingPolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.UpdateFilteredGroupingPolicies([][]string{{"frank", "admin"}}, 0, "frank")
+		},
+		call:      formatCall("UpdateForUpdatePolicies", "g", "g", [][]string{{"frank", "data2_admin"}}, [][]string{{"frank", "admin"}}),
+		updatable: true,
+	},
+	{
+		name:  "RemoveGroupingPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.RemoveGroupingPolicy("eve", "admin") },
+		call:  formatCall("UpdateForRemovePolicy", "g", "g", []string{"eve", "admin"}),
+	},
+	{
+		name: "RemoveGroupingPolicies",
+		apply: func(e *casbin.Enforcer) (bool, error) {
+			return e.RemoveGroupingPolicies([][]string{{"bob", "data2_admin"}})
+		},
+		call: formatCall("UpdateForRemovePolicies", "g", "g", [][]string{{"bob", "data2_admin"}}),
+	},
+	{
+		name:  "RemoveFilteredGroupingPolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return e.RemoveFilteredGroupingPolicy(1, "data2_admin") },
+		call:  formatCall("UpdateForRemoveFilteredPolicy", "g", "g", 1, []string{"data2_admin"}),
+	},
+	{
+		name:  "SavePolicy",
+		apply: func(e *casbin.Enforcer) (bool, error) { return true, e.SavePolicy() },
+		call:  formatCall("UpdateForSavePolicy"),
+	},
+}
+
+// Run runs the conformance tests of the watchers created by config.NewWatchers.
+func Run(t *testing.T, config Config) {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = time.Second
+	}
+
+	w1, w2 := config.NewWatchers(t)
+	defer w1.Close()
+	// w2 is closed by the Close test, or at the end if it does not run
+	var closeOnce sync.Once
+	closeW2 := func() { closeOnce.Do(w2.Close) }
+	defer closeW2()
+	_, isWatcherEx := w1.(persist.WatcherEx)
+	_, isUpdatable := w1.(persist.UpdatableWatcher)
+	_, isInstance := w1.(persist.InstanceWatcher)
+
+	dir, err := os.MkdirTemp("", "casbin-watchertest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	e := newTestEnforcer(t, dir)
+	r := &recorder{watcher: w1}
+	if err := e.SetWatcher(r.wrap()); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := make(chan string, 2*len(mutations))
+	if err := w2.SetUpdateCallback(func(message string) {
+		select {
+		case messages <- message:
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if isInstance && r.instanceID != e.GetInstanceID() {
+		t.Errorf("SetWatcher set the instance ID %q, supposed to be %q", r.instanceID, e.GetInstanceID())
+	}
+
+	var sequence uint64
+	for _, m := range mutations {
+		m := m
+		t.Run(m.name, func(t *testing.T) {
+			ok, err := m.apply(e)
+			if err != nil {
+				t.Fatalf("%s: %v", m.name, err)
+			}
+			if !ok {
+				t.Fatalf("%s did not change the policy", m.name)
+			}
+
+			expected := formatCall("Update")
+			if m.updatable && isUpdatable || !m.updatable && isWatcherEx {
+				expected = m.call
+			}
+			if calls := r.takeCalls(); len(calls) != 1 || calls[0] != expected {
+				t.Errorf("%s called the watcher with %v, supposed to be [%s]", m.name, calls, expected)
+			}
+
+			select {
+			case data := <-messages:
+				if isInstance {
+					// the messages of an instance watcher are stamped in sequence
+					message, err := persist.DecodeWatcherMessage(data)
+					if err != nil {
+						t.Fatalf("the message of %s cannot be decoded: %v", m.name, err)
+					}
+					if message.Source != e.GetInstanceID() || sequence != 0 && message.Sequence != sequence+1 || message.Sequence == 0 {
+						t.Errorf("the message of %s is stamped with %q and %d, supposed to be %q and %d",
+							m.name, message.Source, message.Sequence, e.GetInstanceID(), sequence+1)
+					}
+					sequence = message.Sequence
+				}
+			case <-time.After(timeout):
+				t.Errorf("the other watcher did not receive a message of %s", m.name)
+			}
+		})
+	}
+
+	t.Run("Close", func(t *testing.T) {
+		closeW2()
+		// the messages received before Close are ignored
+		for len(messages) > 0 {
+			<-messages
+		}
+		if _, err := e.AddPolicy("eve", "data5", "read"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case message := <-messages:
+			t.Errorf("the callback of a closed watcher was called with %q", message)
+		case <-time.After(timeout / 10):
+		}
+	})
+}
+
+// examplesDir returns the examples directory of the module, next to the source of this package.
+func examplesDir(t *testing.T) string {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("cannot find the examples directory")
+	}
+	return filepath.Join(filepath.Dir(file), "..", "..", "examples")
+}
+
+// newTestEnforcer returns an enforcer auto-saving to a copy of the example policy in dir.
+func newTestEnforcer(t *testing.T, dir string) *casbin.Enforcer {
+	t.Helper()
+	policy, err := os.ReadFile(filepath.Join(examplesDir(t), "rbac_policy.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "policy.csv")
+	if err = os.WriteFile(path, policy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := fileadapter.NewAdapter(path)
+	a.EnableAutoSave(true)
+	e, err := casbin.NewEnforcer(filepath.Join(examplesDir(t), "rbac_model.conf"), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func formatCall(method string, args ...interface{}) string {
+	return fmt.Sprintf("%s%v", method, args)
+}
+
+// recorder records the calls of a watcher before forwarding them.
+type recorder struct {
+	watcher    persist.Watcher
+	mutex      sync.Mutex
+	calls      []string
+	instanceID string
+}
+
+// updatable is the part of persist.UpdatableWatcher not in persist.WatcherEx.
+type updatable interface {
+	UpdateForUpdatePolicy(sec string, ptype string, oldRule, newRule []string) error
+	UpdateForUpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error
+}
+
+// instance is the part of persist.InstanceWatcher not in persist.Watcher.
+type instance interface {
+	SetInstanceID(id string)
+}
+
+// wrap returns the recorder implementing the same watcher interfaces as the recorded watcher.
+func (r *recorder) wrap() persist.Watcher {
+	_, isWatcherEx := r.watcher.(persist.WatcherEx)
+	_, isUpdatable := r.watcher.(persist.UpdatableWatcher)
+	_, isInstance := r.watcher.(persist.InstanceWatcher)
+	switch {
+	case isWatcherEx && isUpdatable && isInstance:
+		return struct {
+			persist.WatcherEx
+			updatable
+			instance
+		}{r, r, r}
+	case isWatcherEx && isUpdatable:
+		return struct {
+			persist.WatcherEx
+			updatable
+		}{r, r}
+	case isWatcherEx && isInstance:
+		return struct {
+			persist.WatcherEx
+			instance
+		}{r, r}
+	case isWatcherEx:
+		return struct{ persist.WatcherEx }{r}
+	case isUpdatable && isInstance:
+		return struct {
+			persist.UpdatableWatcher
+			instance
+		}{r, r}
+	case isUpdatable:
+		return struct{ persist.UpdatableWatcher }{r}
+	case isInstance:
+		return struct{ persist.InstanceWatcher }{r}
+	default:
+		return struct{ persist.Watcher }{r}
+	}
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, formatCall(method, args...))
+}
+
+func (r *recorder) takeCalls() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+func (r *recorder) SetUpdateCallback(callback func(string)) error {
+	return r.watcher.SetUpdateCallback(callback)
+}
+
+func (r *recorder) Update() error {
+	r.record("Update")
+	return r.watcher.Update()
+}
+
+func (r *recorder) Close() {
+	r.watcher.Close()
+}
+
+func (r *recorder) SetInstanceID(id string) {
+	r.mutex.Lock()
+	r.instanceID = id
+	r.mutex.Unlock()
+	r.watcher.(persist.InstanceWatcher).SetInstanceID(id)
+}
+
+func (r *recorder) UpdateForAddPolicy(sec, ptype string, params ...string) error {
+	r.record("UpdateForAddPolicy", sec, ptype, params)
+	return r.watcher.(persist.WatcherEx).UpdateForAddPolicy(sec, ptype, params...)
+}
+
+func (r *recorder) UpdateForRemovePolicy(sec, ptype string, params ...string) error {
+	r.record("UpdateForRemovePolicy", sec, ptype, params)
+	return r.watcher.(persist.WatcherEx).UpdateForRemovePolicy(sec, ptype, params...)
+}
+
+func (r *recorder) UpdateForRemoveFilteredPolicy(sec, ptype string, fieldIndex int, fieldValues ...string) error {
+	r.record("UpdateForRemoveFilteredPolicy", sec, ptype, fieldIndex, fieldValues)
+	return r.watcher.(persist.WatcherEx).UpdateForRemoveFilteredPolicy(sec, ptype, fieldIndex, fieldValues...)
+}
+
+func (r *recorder) UpdateForSavePolicy(model model.Model) error {
+	r.record("UpdateForSavePolicy")
+	return r.watcher.(persist.WatcherEx).UpdateForSavePolicy(model)
+}
+
+func (r *recorder) UpdateForAddPolicies(sec string, ptype string, rules ...[]string) error {
+	r.record("UpdateForAddPolicies", sec, ptype, rules)
+	return r.watcher.(persist.WatcherEx).UpdateForAddPolicies(sec, ptype, rules...)
+}
+
+func (r *recorder) UpdateForRemovePolicies(sec string, ptype string, rules ...[]string) error {
+	r.record("UpdateForRemovePolicies", sec, ptype, rules)
+	return r.watcher.(persist.WatcherEx).UpdateForRemovePolicies(sec, ptype, rules...)
+}
+
+func (r *recorder) UpdateForUpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	r.record("UpdateForUpdatePolicy", sec, ptype, oldRule, newRule)
+	return r.watcher.(persist.UpdatableWatcher).UpdateForUpdatePolicy(sec, ptype, oldRule, newRule)
+}
+
+func (r *recorder) UpdateForUpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	r.record("UpdateForUpdatePolicies", sec, ptype, oldRules, newRules)
+	return r.watcher.(persist.UpdatableWatcher).UpdateForUpdatePolicies(sec, ptype, oldRules, newRules)
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/casbin/casbin/v3/errors"
+	"github.com/casbin/casbin/v3/model"
+	stringadapter "github.com/casbin/casbin/v3/persist/string-adapter"
+)
+
+func testPolicySchemaViolation(t *testing.T, err error, field string) {
+	t.Helper()
+	var violation *errors.PolicySchemaViolationError
+	if !stderrors.Is(err, errors.ErrPolicySchemaViolation) || !stderrors.As(err, &violation) || violation.Field != field {
+		t.Errorf("error = %v, supposed to be a violation of field %s", err, field)
+	}
+}
+
+func testSchemaEnforce(t *testing.T, e *Enforcer, rvals []interface{}, res bool) {
+	t.Helper()
+	if ok, err := e.Enforce(rvals...); err != nil || ok != res {
+		t.Errorf("%v: %t, %v, supposed to be %t", rvals, ok, err, res)
+	}
+}
+
+func TestPolicySchema(t *testing.T) {
+	e, err := NewEnforcer("examples/rbac_with_schema_model.conf", "examples/rbac_with_schema_policy.csv")
+	if err != nil {
+		t.Fatalf("NewEnforcer: %v", err)
+	}
+	testSchemaEnforce(t, e, []interface{}{"alice", "/data/1", "GET", "192.168.1.1"}, true)
+	testSchemaEnforce(t, e, []interface{}{"alice", "/alice/1", "DELETE", "10.0.0.1"}, true)
+	testSchemaEnforce(t, e, []interface{}{"bob", "/data/1", "GET", "192.168.1.1"}, false)
+
+	_, err = e.AddPolicy("bob", "/data/*", "GTE", "192.168.0.0/16")
+	testPolicySchemaViolation(t, err, "act")
+	_, err = e.AddPolicy("bob", "data", "GET", "192.168.0.0/16")
+	testPolicySchemaViolation(t, err, "obj")
+	_, err = e.AddPolicy("bob", "/data/*", "GET", "192.168.0.0/33")
+	testPolicySchemaViolation(t, err, "ip")
+	if ok, _ := e.HasPolicy("bob", "/data/*", "GTE", "192.168.0.0/16"); ok {
+		t.Error("an invalid rule should not be added")
+	}
+
+	// A batch with an invalid rule is rejected as a whole.
+	_, err = e.AddPolicies([][]string{{"bob", "/data/*", "GET", "10.0.0.1"}, {"bob", "/data/*", "PUT", "10.0.0.1"}})
+	testPolicySchemaViolation(t, err, "act")
+	if ok, _ := e.HasPolicy("bob", "/data/*", "GET", "10.0.0.1"); ok {
+		t.Error("no rule of an invalid batch should be added")
+	}
+
+	_, err = e.UpdatePolicy([]string{"admin", "/data/*", "GET", "192.168.0.0/16"}, []string{"admin", "/data/*", "get", "192.168.0.0/16"})
+	testPolicySchemaViolation(t, err, "act")
+
+	if ok, err := e.AddPolicy("bob", "/data/*", "GET", "10.0.0.0/8"); !ok || err != nil {
+		t.Errorf("AddPolicy() = %v, %v, supposed to add a valid rule", ok, err)
+	}
+	testSchemaEnforce(t, e, []interface{}{"bob", "/data/1", "GET", "10.1.2.3"}, true)
+
+	// Grouping rules are not checked.
+	if ok, err := e.AddGroupingPolicy("bob", "admin"); !ok || err != nil {
+		t.Errorf("AddGroupingPolicy() = %v, %v", ok, err)
+	}
+}
+
+func TestPolicySchemaLoadPolicy(t *testing.T) {
+	m, err := model.NewModelFromFile("examples/rbac_with_schema_model.conf")
+	if err != nil {
+		t.Fatalf("NewModelFromFile: %v", err)
+	}
+	_, err = NewEnforcer(m, stringadapter.NewAdapter("p, alice, /data/*, GET, 10.0.0.1\np, bob, /data/*, raed, 10.0.0.1"))
+	testPolicySchemaViolation(t, err, "act")
+}
+
+func TestValidatePolicies(t *testing.T) {
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, level
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+	if err != nil {
+		t.Fatalf("NewModelFromString: %v", err)
+	}
+	e, err := NewEnforcer(m, stringadapter.NewAdapter("p, alice, /data, read, 1\np, bob, /data, raed, 2\np, cathy, data, write, high"))
+	if err != nil {
+		t.Fatalf("NewEnforcer: %v", err)
+	}
+	if violations := e.ValidatePolicies(); len(violations) != 0 {
+		t.Errorf("ValidatePolicies() = %v, supposed to be empty without schema", violations)
+	}
+
+	// Declaring a schema for existing data reports all its violations.
+	e.GetModel().AddDef("s", "s", "obj: path, act: enum(read|write), level: int")
+	violations := e.ValidatePolicies()
+	if len(violations) != 3 {
+		t.Fatalf("ValidatePolicies() = %v, supposed to have 3 violations", violations)
+	}
+	testPolicySchemaViolation(t, violations[0], "act")
+	testPolicySchemaViolation(t, violations[1], "obj")
+	testPolicySchemaViolation(t, violations[2], "level")
+}
+
+func TestPolicySchemaTypes(t *testing.T) {
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, pattern, priority, expires
+
+[policy_schema]
+s = sub: string, pattern: regex, priority: int, expires: time
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+	if err != nil {
+		t.Fatalf("NewModelFromString: %v", err)
+	}
+	if err := m.ValidatePolicySchema("p", "p", [][]string{{"alice", "data1", "read", "^/data/.*$", "-1", "2026-01-02T15:04:05Z"}}); err != nil {
+		t.Errorf("ValidatePolicySchema() = %v, supposed to be valid", err)
+	}
+	testPolicySchemaViolation(t, m.ValidatePolicySchema("p", "p", [][]string{{"alice", "data1", "read", "^(/data", "1", "2026-01-02T15:04:05Z"}}), "pattern")
+	testPolicySchemaViolation(t, m.ValidatePolicySchema("p", "p", [][]string{{"alice", "data1", "read", ".*", "1.5", "2026-01-02T15:04:05Z"}}), "priority")
+	testPolicySchemaViolation(t, m.ValidatePolicySchema("p", "p", [][]string{{"alice", "data1", "read", ".*", "1", "2026-01-02"}}), "expires")
+
+	// The schema is compiled when the model is loaded and kept on the policy definition.
+	if schema := m["p"]["p"].PolicySchema; len(schema) != 4 || schema[1].Field != "pattern" || schema[1].Index != 3 {
+		t.Errorf("PolicySchema = %v, supposed to be compiled", schema)
+	}
+	if schema := m.Copy()["p"]["p"].PolicySchema; len(schema) != 4 {
+		t.Errorf("PolicySchema of the copy = %v, supposed to be compiled", schema)
+	}
+	// A schema added later is compiled too, and its errors are reported.
+	m.AddDef("s", "s", "act: number")
+	if err := m.ValidatePolicySchema("p", "p", [][]string{{"alice", "data1", "read", ".*", "1", "2026-01-02"}}); !stderrors.Is(err, errors.ErrInvalidPolicySchemaDefinition) {
+		t.Errorf("ValidatePolicySchema() = %v, supposed to report the invalid schema", err)
+	}
+
+	for _, schema := range []string{"owner: string", "act: number", "act: enum()", "act: int(8)", "act"} {
+		_, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_schema]
+s = ` + schema + `
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+		if !stderrors.Is(err, errors.ErrInvalidPolicySchemaDefinition) {
+			t.Errorf("schema %q: error = %v, supposed to be invalid", schema, err)
+		}
+	}
+}
+
+func TestPolicySchemaCtx(t *testing.T) {
+	e, err := NewContextEnforcer("examples/rbac_with_schema_model.conf", "examples/rbac_with_schema_policy.csv")
+	if err != nil {
+		t.Fatalf("NewContextEnforcer: %v", err)
+	}
+	ctx := context.Background()
+
+	_, err = e.AddPolicyCtx(ctx, "bob", "/data/*", "GTE", "192.168.0.0/16")
+	testPolicySchemaViolation(t, err, "act")
+	_, err = e.AddPoliciesCtx(ctx, [][]string{{"bob", "/data/*", "GET", "10.0.0.1"}, {"bob", "data", "GET", "10.0.0.1"}})
+	testPolicySchemaViolation(t, err, "obj")
+	_, err = e.UpdatePolicyCtx(ctx, []string{"admin", "/data/*", "GET", "192.168.0.0/16"}, []string{"admin", "/data/*", "GET", "192.168.0.0/33"})
+	testPolicySchemaViolation(t, err, "ip")
+	_, err = e.UpdatePoliciesCtx(ctx, [][]string{{"admin", "/data/*", "GET", "192.168.0.0/16"}}, [][]string{{"admin", "/data/*", "get", "192.168.0.0/16"}})
+	testPolicySchemaViolation(t, err, "act")
+	_, err = e.UpdateFilteredPoliciesCtx(ctx, [][]string{{"admin", "/data/*", "get", "192.168.0.0/16"}}, 0, "admin")
+	testPolicySchemaViolation(t, err, "act")
+
+	if ok, _ := e.HasPolicy("bob", "/data/*", "GTE", "192.168.0.0/16"); ok {
+		t.Error("an invalid rule should not be added")
+	}
+	if ok, _ := e.HasPolicy("bob", "/data/*", "GET", "10.0.0.1"); ok {
+		t.Error("no rule of an invalid batch should be added")
+	}
+	if ok, _ := e.HasPolicy("admin", "/data/*", "GET", "192.168.0.0/16"); !ok {
+		t.Error("a rule should not be replaced by an invalid rule")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/casbin/govaluate"
+)
+
+// RequestRole is a role asserted for a subject during a single request.
+type RequestRole struct {
+	// PType is the grouping policy type the role belongs to (default: "g").
+	PType string
+	// Subject is the user holding the role.
+	Subject string
+	// Role is the role asserted for the subject.
+	Role string
+	// Domain restricts the role to a domain, leave it empty to apply it to every domain.
+	Domain string
+}
+
+// RequestRoles holds the roles asserted for a single request, e.g. by an identity token.
+// It can be passed as an element of the parameter "rvals" before the request values,
+// in which case g() consults it in addition to the role manager, which is left untouched.
+type RequestRoles []RequestRole
+
+// NewRequestRoles creates request roles binding subject to roles of the grouping policy "g" in every domain.
+func NewRequestRoles(subject string, roles ...string) RequestRoles {
+	requestRoles := make(RequestRoles, 0, len(roles))
+	for _, role := range roles {
+		requestRoles = append(requestRoles, RequestRole{Subject: subject, Role: role})
+	}
+	return requestRoles
+}
+
+// GetCacheKey implements CacheableParam, the key does not depend on the order of the roles.
+func (r RequestRoles) GetCacheKey() string {
+	keys := make([]string, 0, len(r))
+	for _, role := range r {
+		keys = append(keys, role.ptype()+"\x00"+role.Subject+"\x00"+role.Role+"\x00"+role.Domain)
+	}
+	sort.Strings(keys)
+	return "RequestRoles{" + strings.Join(keys, "\x01") + "}"
+}
+
+func (r RequestRole) ptype() string {
+	if r.PType == "" {
+		return "g"
+	}
+	return r.PType
+}
+
+// generateGFunction wraps the g() function of ptype so that a subject also has a role if one of its request roles
+// is the role itself or inherits it through the role manager.
+func (r RequestRoles) generateGFunction(ptype string, g govaluate.ExpressionFunction) govaluate.ExpressionFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		v, err := g(args...)
+		if err != nil {
+			return nil, err
+		}
+		if hasLink, ok := v.(bool); ok && hasLink || len(args) < 2 {
+			return v, nil
+		}
+
+		name1, ok1 := args[0].(string)
+		name2, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return v, nil
+		}
+		domain := ""
+		if len(args) > 2 {
+			domain, _ = args[2].(string)
+		}
+
+		for _, role := range r {
+			if role.ptype() != ptype || role.Subject != name1 || role.Domain != "" && role.Domain != domain {
+				continue
+			}
+			if role.Role == name2 {
+				return true, nil
+			}
+			linkArgs := append([]interface{}{role.Role}, args[1:]...)
+			v, err = g(linkArgs...)
+			if err != nil {
+				return nil, err
+			}
+			if hasLink, ok := v.(bool); ok && hasLink {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"testing"
+)
+
+func testEnforceWithRoles(t *testing.T, e IEnforcer, roles RequestRoles, sub, obj, act string, res bool) {
+	t.Helper()
+	var myRes bool
+	var err error
+	switch enforcer := e.(type) {
+	case *Enforcer:
+		myRes, err = enforcer.EnforceWithRoles(roles, sub, obj, act)
+	case *CachedEnforcer:
+		myRes, err = enforcer.EnforceWithRoles(roles, sub, obj, act)
+	case *SyncedEnforcer:
+		myRes, err = enforcer.EnforceWithRoles(roles, sub, obj, act)
+	}
+	if err != nil {
+		t.Errorf("EnforceWithRoles Error: %s", err)
+	} else if myRes != res {
+		t.Errorf("%v, %s, %s, %s: %t, supposed to be %t", roles, sub, obj, act, myRes, res)
+	}
+}
+
+func TestEnforceWithRoles(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	_, _ = e.AddGroupingPolicy("manager", "data2_admin")
+
+	testEnforceWithRoles(t, e, NewRequestRoles("bob", "data2_admin"), "bob", "data2", "read", true)
+	testEnforceWithRoles(t, e, NewRequestRoles("bob", "manager"), "bob", "data2", "write", true)
+	testEnforceWithRoles(t, e, NewRequestRoles("bob", "data2_admin"), "cathy", "data2", "read", false)
+	testEnforceWithRoles(t, e, nil, "alice", "data2", "read", true)
+
+	// The role manager is not modified.
+	testEnforce(t, e, "bob", "data2", "read", false)
+	if ok, _ := e.HasRoleForUser("bob", "data2_admin"); ok {
+		t.Error("request roles must not be persisted")
+	}
+
+	// Roles of other grouping policy types are ignored.
+	testEnforceWithRoles(t, e, RequestRoles{{PType: "g2", Subject: "bob", Role: "data2_admin"}}, "bob", "data2", "read", false)
+}
+
+func TestEnforceWithRolesInDomain(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_with_domains_model.conf", "examples/rbac_with_domains_policy.csv")
+
+	roles := RequestRoles{{Subject: "bob", Role: "admin", Domain: "domain1"}}
+	ok, _ := e.EnforceWithRoles(roles, "bob", "domain1", "data1", "read")
+	if !ok {
+		t.Error("bob should be admin of domain1 for this request")
+	}
+	ok, _ = e.EnforceWithRoles(roles, "cathy", "domain1", "data1", "read")
+	if ok {
+		t.Error("cathy has no request role")
+	}
+
+	roles = RequestRoles{{Subject: "alice", Role: "admin", Domain: "domain2"}}
+	ok, _ = e.EnforceWithRoles(roles, "alice", "domain1", "data1", "read")
+	if !ok {
+		t.Error("alice should keep her stored roles")
+	}
+	ok, _ = e.EnforceWithRoles(roles, "alice", "domain2", "data2", "read")
+	if !ok {
+		t.Error("alice should be admin of domain2 for this request")
+	}
+	testDomainEnforce(t, e, "alice", "domain2", "data2", "read", false)
+
+	// A role without domain applies to every domain.
+	roles = RequestRoles{{Subject: "cathy", Role: "admin"}}
+	ok, _ = e.EnforceWithRoles(roles, "cathy", "domain2", "data2", "write")
+	if !ok {
+		t.Error("cathy should be admin of every domain for this request")
+	}
+}
+
+func TestCachedEnforceWithRoles(t *testing.T) {
+	e, _ := NewCachedEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	testEnforceWithRoles(t, e, NewRequestRoles("bob", "data2_admin"), "bob", "data2", "read", true)
+	testEnforceCache(t, e, "bob", "data2", "read", false)
+	testEnforceWithRoles(t, e, NewRequestRoles("bob", "data2_admin"), "bob", "data2", "read", true)
+	testEnforceWithRoles(t, e, NewRequestRoles("bob"), "bob", "data2", "read", false)
+
+	a := RequestRoles{{Subject: "bob", Role: "r1"}, {Subject: "bob", Role: "r2"}}
+	b := RequestRoles{{Subject: "bob", Role: "r2"}, {Subject: "bob", Role: "r1"}}
+	if a.GetCacheKey() != b.GetCacheKey() {
+		t.Error("the cache key should not depend on the order of the roles")
+	}
+}
+
+func TestSyncedEnforceWithRoles(t *testing.T) {
+	e, _ := NewSyncedEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			if i%2 == 0 {
+				testEnforceWithRoles(t, e, NewRequestRoles("bob", "data2_admin"), "bob", "data2", "read", true)
+			} else {
+				testEnforceSync(t, e, "bob", "data2", "read", false)
+			}
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+}
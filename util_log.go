@@ -15,6 +15,7 @@
 package casbin
 
 import (
+	"github.com/casbin/casbin/v3/constant"
 	"github.com/casbin/casbin/v3/log"
 	"github.com/casbin/casbin/v3/model"
 )
@@ -98,6 +99,24 @@ func (e *Enforcer) createEnforceLogEntry(rvals []interface{}) *log.LogEntry {
 	entry := &log.LogEntry{
 		EventType: log.EventEnforce,
 	}
+	// Skip the options passed before the request values.
+	for len(rvals) > 0 {
+		switch rvals[0].(type) {
+		case EnforceContext, RequestRoles, *batchEnforceState, *Obligations, *enforceOutcome, *enforceReason:
+			rvals = rvals[1:]
+			continue
+		}
+		break
+	}
+	if len(rvals) == 1 {
+		if fields, ok := namedRequestFields(rvals[0], true); ok {
+			entry.Subject, _ = fields[constant.SubjectIndex].(string)
+			entry.Object, _ = fields[constant.ObjectIndex].(string)
+			entry.Action, _ = fields[constant.ActionIndex].(string)
+			entry.Domain, _ = fields[constant.DomainIndex].(string)
+			return entry
+		}
+	}
 	if len(rvals) > 0 {
 		if s, isString := rvals[0].(string); isString {
 			entry.Subject = s
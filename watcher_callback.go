@@ -0,0 +1,111 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"sync"
+
+	"github.com/casbin/casbin/v3/persist"
+)
+
+// NewWatcherCallback returns an update callback applying the policy changes of the watcher messages
+// written by persist.EncodeWatcherMessage to e with its Self methods, which do not notify the watcher again.
+// The policy is reloaded for an Update or a SavePolicy, for a message of another format,
+// and when a change cannot be applied, e.g. the update of a rule missing from the policy of e.
+// The messages stamped by a persist.InstanceWatcher are ignored if they come from e or are not newer than the last
+// message received from their source, and the policy is reloaded if a message of their source was missed.
+// It is the callback set by Enforcer.SetWatcher, the cached enforcers also invalidate their cache after each message.
+func NewWatcherCallback(e IEnforcer) func(string) {
+	var mutex sync.Mutex
+	sequences := make(map[string]uint64)
+	return func(data string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		message, err := persist.DecodeWatcherMessage(data)
+		if err != nil {
+			_ = e.LoadPolicy()
+			return
+		}
+
+		if message.Source != "" && message.Sequence != 0 {
+			if ie, ok := e.(interface{ GetInstanceID() string }); ok && ie.GetInstanceID() == message.Source {
+				return
+			}
+			last, ok := sequences[message.Source]
+			if ok && message.Sequence <= last {
+				// a duplicate, or a late message whose change is covered by the reload of the gap it left
+				return
+			}
+			sequences[message.Source] = message.Sequence
+			if ok && message.Sequence > last+1 {
+				// a message was missed
+				_ = e.LoadPolicy()
+				return
+			}
+		}
+
+		if !applyWatcherMessage(e, message) {
+			_ = e.LoadPolicy()
+		}
+	}
+}
+
+// setsWatcherCallback returns whether SetWatcher sets the update callback of watcher.
+// The callback of a persist.WatcherEx may have been set by the caller before SetWatcher, so it is left alone,
+// unless the watcher is a persist.InstanceWatcher, whose stamped messages are meant for NewWatcherCallback.
+func setsWatcherCallback(watcher persist.Watcher) bool {
+	if _, ok := watcher.(persist.WatcherEx); !ok {
+		return true
+	}
+	_, ok := watcher.(persist.InstanceWatcher)
+	return ok
+}
+
+// invalidatingWatcherCallback returns the update callback of NewWatcherCallback for e,
+// invalidating the cached decisions after each message.
+func invalidatingWatcherCallback(e interface {
+	IEnforcer
+	InvalidateCache() error
+}) func(string) {
+	callback := NewWatcherCallback(e)
+	return func(data string) {
+		callback(data)
+		_ = e.InvalidateCache()
+	}
+}
+
+// applyWatcherMessage applies the policy change of message to e, it returns false if the policy should be reloaded.
+func applyWatcherMessage(e IEnforcer, message *persist.WatcherMessage) bool {
+	var ok bool
+	var err error
+	switch message.Method {
+	case persist.UpdateForAddPolicy, persist.UpdateForAddPolicies:
+		// the rules already added are skipped
+		_, err = e.SelfAddPoliciesEx(message.Sec, message.PType, message.Rules)
+		return err == nil
+	case persist.UpdateForRemovePolicy, persist.UpdateForRemovePolicies:
+		_, err = e.SelfRemovePolicies(message.Sec, message.PType, message.Rules)
+		return err == nil
+	case persist.UpdateForRemoveFilteredPolicy:
+		_, err = e.SelfRemoveFilteredPolicy(message.Sec, message.PType, message.FieldIndex, message.FieldValues...)
+		return err == nil
+	case persist.UpdateForUpdatePolicy, persist.UpdateForUpdatePolicies:
+		ok, err = e.SelfUpdatePolicies(message.Sec, message.PType, message.Rules, message.NewRules)
+		return ok && err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casbin
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v3/persist"
+)
+
+func testWatcherMessage(t *testing.T, message persist.WatcherMessage) string {
+	t.Helper()
+	data, err := persist.EncodeWatcherMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWatcherCallback(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	callback := NewWatcherCallback(e)
+
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForAddPolicies, Sec: "p", PType: "p",
+		Rules: [][]string{{"eve", "data3", "read"}, {"alice", "data1", "read"}}}))
+	testHasPolicy(t, e, []string{"eve", "data3", "read"}, true)
+
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForAddPolicy, Sec: "g", PType: "g",
+		Rules: [][]string{{"eve", "data2_admin"}}}))
+	testEnforce(t, e, "eve", "data2", "write", true)
+
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForRemovePolicy, Sec: "p", PType: "p",
+		Rules: [][]string{{"eve", "data3", "read"}}}))
+	testHasPolicy(t, e, []string{"eve", "data3", "read"}, false)
+
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForUpdatePolicy, Sec: "p", PType: "p",
+		Rules: [][]string{{"alice", "data1", "read"}}, NewRules: [][]string{{"alice", "data1", "write"}}}))
+	testGetPolicy(t, e, [][]string{
+		{"alice", "data1", "write"},
+		{"bob", "data2", "write"},
+		{"data2_admin", "data2", "read"},
+		{"data2_admin", "data2", "write"},
+	})
+
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForRemoveFilteredPolicy, Sec: "p", PType: "p",
+		FieldIndex: 1, FieldValues: []string{"data2"}}))
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "write"}})
+
+	// the policy is reloaded for a SavePolicy
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForSavePolicy}))
+	testGetPolicy(t, e, [][]string{
+		{"alice", "data1", "read"},
+		{"bob", "data2", "write"},
+		{"data2_admin", "data2", "read"},
+		{"data2_admin", "data2", "write"},
+	})
+	testEnforce(t, e, "eve", "data2", "write", false)
+
+	// the policy is reloaded for an update of a missing rule
+	_, _ = e.SelfAddPolicy("p", "p", []string{"eve", "data3", "read"})
+	callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForUpdatePolicy, Sec: "p", PType: "p",
+		Rules: [][]string{{"bob", "data3", "read"}}, NewRules: [][]string{{"bob", "data3", "write"}}}))
+	testHasPolicy(t, e, []string{"eve", "data3", "read"}, false)
+	testHasPolicy(t, e, []string{"bob", "data3", "write"}, false)
+
+	// the policy is reloaded for a message of another format
+	_, _ = e.SelfAddPolicy("p", "p", []string{"eve", "data3", "read"})
+	callback("")
+	testHasPolicy(t, e, []string{"eve", "data3", "read"}, false)
+}
+
+// sampleInstanceWatcherEx is a WatcherEx stamping its messages, whose callback is set by SetWatcher.
+type sampleInstanceWatcherEx struct {
+	SampleWatcherEx
+	id string
+}
+
+func (w *sampleInstanceWatcherEx) SetInstanceID(id string) {
+	w.id = id
+}
+
+func TestSetWatcherCallback(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+
+	// the callback of a WatcherEx set before SetWatcher is kept
+	called := false
+	sampleWatcherEx := &SampleWatcherEx{}
+	_ = sampleWatcherEx.SetUpdateCallback(func(string) { called = true })
+	if err := e.SetWatcher(sampleWatcherEx); err != nil {
+		t.Fatal(err)
+	}
+	sampleWatcherEx.callback("")
+	if !called {
+		t.Error("SetWatcher replaced the callback of the WatcherEx")
+	}
+
+	instanceWatcher := &sampleInstanceWatcherEx{}
+	if err := e.SetWatcher(instanceWatcher); err != nil {
+		t.Fatal(err)
+	}
+	if instanceWatcher.id != e.GetInstanceID() {
+		t.Errorf("SetWatcher set the instance ID %q, supposed to be %q", instanceWatcher.id, e.GetInstanceID())
+	}
+	instanceWatcher.callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForAddPolicy, Sec: "p", PType: "p",
+		Rules: [][]string{{"eve", "data3", "read"}}}))
+	testEnforce(t, e, "eve", "data3", "read", true)
+}
+
+func TestSetWatcherCallbackCached(t *testing.T) {
+	removeAlice := testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForRemovePolicy, Sec: "p", PType: "p",
+		Rules: [][]string{{"alice", "data1", "read"}}})
+
+	e, _ := NewCachedEnforcer("examples/basic_model.conf", "examples/basic_policy.csv")
+	w := &SampleWatcher{}
+	if err := e.SetWatcher(w); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := e.Enforce("alice", "data1", "read"); !ok {
+		t.Fatal("alice should read data1")
+	}
+	w.callback(removeAlice)
+	if ok, _ := e.Enforce("alice", "data1", "read"); ok {
+		t.Error("the cached decision should be invalidated by the watcher message")
+	}
+
+	se, _ := NewSyncedCachedEnforcer("examples/basic_model.conf", "examples/basic_policy.csv")
+	sw := &SampleWatcher{}
+	if err := se.SetWatcher(sw); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := se.Enforce("alice", "data1", "read"); !ok {
+		t.Fatal("alice should read data1")
+	}
+	sw.callback(removeAlice)
+	if ok, _ := se.Enforce("alice", "data1", "read"); ok {
+		t.Error("the cached decision should be invalidated by the watcher message")
+	}
+}
+
+func TestWatcherCallbackSequence(t *testing.T) {
+	e, _ := NewEnforcer("examples/rbac_model.conf", "examples/rbac_policy.csv")
+	callback := NewWatcherCallback(e)
+	addPolicy := func(source string, sequence uint64, rule ...string) {
+		callback(testWatcherMessage(t, persist.WatcherMessage{Method: persist.UpdateForAddPolicy, Sec: "p", PType: "p",
+			Rules: [][]string{rule}, Source: source, Sequence: sequence}))
+	}
+
+	// a rule only in the policy of e, removed by a reload
+	_, _ = e.SelfAddPolicy("p", "p", []string{"local", "data1", "read"})
+
+	// the messages of e are ignored
+	addPolicy(e.GetInstanceID(), 1, "eve", "data1", "read")
+	testHasPolicy(t, e, []string{"eve", "data1", "read"}, false)
+
+	// the messages of each source are applied in sequence
+	addPolicy("node1", 7, "eve", "data1", "read")
+	addPolicy("node2", 1, "eve", "data2", "read")
+	addPolicy("node1", 8, "eve", "data3", "read")
+	testHasPolicy(t, e, []string{"eve", "data1", "read"}, true)
+	testHasPolicy(t, e, []string{"eve", "data2", "read"}, true)
+	testHasPolicy(t, e, []string{"eve", "data3", "read"}, true)
+	testHasPolicy(t, e, []string{"local", "data1", "read"}, true)
+
+	// a duplicate message is ignored
+	_, _ = e.SelfRemovePolicy("p", "p", []string{"eve", "data3", "read"})
+	addPolicy("node1", 8, "eve", "data3", "read")
+	testHasPolicy(t, e, []string{"eve", "data3", "read"}, false)
+	testHasPolicy(t, e, []string{"local", "data1", "read"}, true)
+
+	// the policy is reloaded when a message is missed
+	addPolicy("node1", 10, "eve", "data4", "read")
+	testHasPolicy(t, e, []string{"local", "data1", "read"}, false)
+	testHasPolicy(t, e, []string{"eve", "data1", "read"}, false)
+
+	addPolicy("node1", 11, "eve", "data5", "read")
+	testHasPolicy(t, e, []string{"eve", "data5", "read"}, true)
+
+	// a late message is ignored, and does not make the next message look like a gap
+	_, _ = e.SelfAddPolicy("p", "p", []string{"local", "data2", "read"})
+	addPolicy("node1", 9, "eve", "data6", "read")
+	testHasPolicy(t, e, []string{"eve", "data6", "read"}, false)
+	testHasPolicy(t, e, []string{"local", "data2", "read"}, true)
+
+	addPolicy("node1", 12, "eve", "data7", "read")
+	testHasPolicy(t, e, []string{"eve", "data7", "read"}, true)
+	testHasPolicy(t, e, []string{"local", "data2", "read"}, true)
+}